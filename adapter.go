@@ -0,0 +1,51 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"log/slog"
+	"net/http"
+)
+
+// defaultContextConfig is used for Handlers that are served without a Server.
+var defaultContextConfig = newContextConfig()
+
+// WrapH adapts the http.Handler h to a Handler. The returned Response calls h when it is written,
+// so headers set by middleware are sent while h writes status code and body.
+func WrapH(h http.Handler) Handler {
+	return func(c *Context) *Response {
+		return serveHTTP(h, c.r)
+	}
+}
+
+// WrapF adapts the http.HandlerFunc f to a Handler. See WrapH.
+func WrapF(f http.HandlerFunc) Handler {
+	return WrapH(f)
+}
+
+// ServeHTTP implements http.Handler, so Handlers can be used in stdlib handler chains.
+// The Handler is served with the default configuration of a Server.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wrap(defaultContextConfig, "", nil, h)(w, r)
+}
+
+// AsHandler returns a http.Handler that writes the Response for every request, e.g. for static responses.
+func (r *Response) AsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err := r.Write(w); err != nil {
+			slog.Error("unable to write response", "error", err.Error())
+		}
+	})
+}
+
+// serveHTTP returns a Response that serves the request with h when it is written.
+// Headers and cookies set on the Response are applied before h is called,
+// while status code and body are written by h.
+func serveHTTP(h http.Handler, r *http.Request) *Response {
+	res := Respond()
+	res.handler = h
+	res.request = r
+	return res
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWrapF(t *testing.T) {
+	s := NewServer().Use(func(c *Context, next Handler) *Response {
+		return next(c).Header("X-Middleware", "yes")
+	})
+	s.GET("/legacy", WrapF(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("legacy"))
+	}))
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/legacy", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "legacy" {
+		t.Errorf("Expected wrapped handler response, got %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Middleware") != "yes" {
+		t.Errorf("Expected middleware header to be set")
+	}
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	var h http.Handler = Handler(func(c *Context) *Response {
+		return Respond().Text("hello " + c.Query("name"))
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?name=alice", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "hello alice" {
+		t.Errorf("Expected hello alice, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestResponse_AsHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/health", Respond().Text("ok").AsHandler())
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("Expected ok, got %d %s", w.Code, w.Body.String())
+	}
+}
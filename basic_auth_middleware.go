@@ -0,0 +1,42 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"crypto/subtle"
+	"strconv"
+)
+
+// BasicAuthUserKey is the context key under which BasicAuthMiddleware stores the authenticated username.
+const BasicAuthUserKey = "srv.basicAuthUser"
+
+// BasicAuthMiddleware requires HTTP Basic Authentication. Requests without credentials or with
+// credentials rejected by validate receive a 401 response with a WWW-Authenticate challenge for realm.
+// The username of authenticated requests is stored in the context under BasicAuthUserKey.
+func BasicAuthMiddleware(realm string, validate func(user, pass string) bool) Middleware {
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+	return func(c *Context, next Handler) *Response {
+		user, pass, ok := c.BasicAuth()
+		if !ok || !validate(user, pass) {
+			return Respond().Unauthorized().WwwHauthenticate(challenge)
+		}
+		c.Set(BasicAuthUserKey, user)
+		return next(c)
+	}
+}
+
+// BasicAuthUsers returns a validate function for BasicAuthMiddleware that accepts the given
+// username to password pairs. Credentials are compared in constant time to avoid timing attacks.
+func BasicAuthUsers(users map[string]string) func(user, pass string) bool {
+	return func(user, pass string) bool {
+		valid := 0
+		for u, p := range users {
+			userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(u))
+			passMatch := subtle.ConstantTimeCompare([]byte(pass), []byte(p))
+			valid |= userMatch & passMatch
+		}
+		return valid == 1
+	}
+}
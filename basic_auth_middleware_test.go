@@ -0,0 +1,40 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBasicAuthMiddleware(t *testing.T) {
+	s := NewServer()
+	s.GET("/admin", func(c *Context) *Response {
+		return Respond().Text(c.MustGet(BasicAuthUserKey).(string))
+	}, BasicAuthMiddleware("admin", BasicAuthUsers(map[string]string{"alice": "secret"})))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/admin", nil)
+	req.SetBasicAuth("alice", "secret")
+	s.Handler().ServeHTTP(w, req)
+	if w.Code != 200 || w.Body.String() != "alice" {
+		t.Errorf("Expected 200 for alice, got %d %s", w.Code, w.Body.String())
+	}
+
+	for _, pass := range []string{"wrong", ""} {
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/admin", nil)
+		if pass != "" {
+			req.SetBasicAuth("alice", pass)
+		}
+		s.Handler().ServeHTTP(w, req)
+		if w.Code != 401 {
+			t.Errorf("Expected 401, got %d", w.Code)
+		}
+		if got := w.Header().Get("WWW-Authenticate"); got != `Basic realm="admin", charset="UTF-8"` {
+			t.Errorf("Expected basic challenge, got %s", got)
+		}
+	}
+}
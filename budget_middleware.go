@@ -0,0 +1,40 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// RequestBudgetHeader carries the remaining time budget of a request in milliseconds.
+const RequestBudgetHeader = "X-Request-Budget"
+
+// BudgetMiddleware limits the time available to handle a request to d. If the request carries a smaller
+// budget in the RequestBudgetHeader, that budget is used instead. The deadline is set on the request context,
+// so handlers can read it via Context.Deadline and pass the context to downstream calls. The remaining budget
+// at the time the handler returned is sent in the RequestBudgetHeader of the response.
+func BudgetMiddleware(d time.Duration) Middleware {
+	return func(c *Context, next Handler) *Response {
+		budget := d
+		if raw := c.Header(RequestBudgetHeader); raw != "" {
+			if ms, err := strconv.ParseInt(raw, 10, 64); err == nil && ms >= 0 && time.Duration(ms)*time.Millisecond < budget {
+				budget = time.Duration(ms) * time.Millisecond
+			}
+		}
+		ctx, cancel := context.WithTimeout(c.r.Context(), budget)
+		c.r = c.r.WithContext(ctx)
+		deadline, _ := ctx.Deadline()
+
+		r := next(c)
+
+		remaining := time.Until(deadline).Milliseconds()
+		if remaining < 0 {
+			remaining = 0
+		}
+		return r.Header(RequestBudgetHeader, strconv.FormatInt(remaining, 10)).AfterWrite(cancel)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBudgetMiddleware(t *testing.T) {
+	var remaining time.Duration
+	s := NewServer().Use(BudgetMiddleware(2 * time.Second))
+	s.GET("/", func(c *Context) *Response {
+		deadline, ok := c.Deadline()
+		if !ok {
+			return Respond().InternalServerError()
+		}
+		remaining = time.Until(deadline)
+		return Respond().NoContent()
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != 204 {
+		t.Fatalf("Expected deadline to be set, got status %d", w.Code)
+	}
+	if remaining <= time.Second || remaining > 2*time.Second {
+		t.Errorf("Expected remaining budget of about 2s, got %s", remaining)
+	}
+	header, err := strconv.Atoi(w.Header().Get(RequestBudgetHeader))
+	if err != nil || header <= 1000 || header > 2000 {
+		t.Errorf("Expected remaining budget header of about 2000ms, got %s", w.Header().Get(RequestBudgetHeader))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestBudgetHeader, "500")
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	if remaining > 500*time.Millisecond {
+		t.Errorf("Expected incoming budget of 500ms to be honored, got %s", remaining)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"strings"
+)
+
+// CanonicalHostConfig configures the CanonicalHostMiddleware.
+type CanonicalHostConfig struct {
+	// Host is the canonical host, e.g. "example.com". If empty, the host of the request is kept.
+	Host string
+	// Scheme is the canonical scheme, e.g. "https". If empty, the scheme of the request is kept.
+	Scheme string
+	// LowercasePath redirects paths containing upper case letters to their lower case form.
+	LowercasePath bool
+}
+
+// CanonicalHostMiddleware redirects requests that don't match the canonical form described by cfg
+// with 308 Permanent Redirect, e.g. from www.example.com to example.com. Path and query are preserved.
+// When proxies are trusted, the scheme and host are taken from the forwarding headers (see
+// Context.ForwardedInfo), so requests forwarded by a TLS-terminating proxy are not redirected again.
+func CanonicalHostMiddleware(cfg CanonicalHostConfig) Middleware {
+	return func(c *Context, next Handler) *Response {
+		info := c.ForwardedInfo()
+		scheme := strings.ToLower(info.Proto)
+		host := info.Host
+		path := c.r.URL.EscapedPath()
+
+		canonicalScheme, canonicalHost, canonicalPath := scheme, host, path
+		if cfg.Scheme != "" {
+			canonicalScheme = cfg.Scheme
+		}
+		if cfg.Host != "" {
+			canonicalHost = cfg.Host
+		}
+		if cfg.LowercasePath {
+			canonicalPath = strings.ToLower(path)
+		}
+		if canonicalScheme == scheme && strings.EqualFold(canonicalHost, host) && canonicalPath == path {
+			return next(c)
+		}
+
+		location := canonicalScheme + "://" + canonicalHost + canonicalPath
+		if c.r.URL.RawQuery != "" {
+			location += "?" + c.r.URL.RawQuery
+		}
+		return Respond().Status(http.StatusPermanentRedirect).Location(location)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCanonicalHostMiddleware(t *testing.T) {
+	s := NewServer().Use(CanonicalHostMiddleware(CanonicalHostConfig{Host: "example.com", LowercasePath: true}))
+	s.GET("/docs/{page}", func(c *Context) *Response {
+		return Respond().Text(c.PathValue("page"))
+	})
+
+	tests := []struct {
+		name     string
+		url      string
+		status   int
+		location string
+	}{
+		{"www", "http://www.example.com/docs/intro?lang=en", http.StatusPermanentRedirect, "http://example.com/docs/intro?lang=en"},
+		{"upper case path", "http://example.com/docs/Intro", http.StatusPermanentRedirect, "http://example.com/docs/intro"},
+		{"canonical", "http://example.com/docs/intro", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.location {
+				t.Errorf("Expected location %s, got %s", tt.location, location)
+			}
+		})
+	}
+}
+
+func TestCanonicalHostMiddleware_Proxied(t *testing.T) {
+	s := NewServer().SetTrustRemoteIdHeaders(true).Use(CanonicalHostMiddleware(CanonicalHostConfig{Scheme: "https", Host: "example.com"}))
+	s.GET("/", func(c *Context) *Response {
+		return Respond().Text("ok")
+	})
+
+	tests := []struct {
+		name     string
+		proto    string
+		status   int
+		location string
+	}{
+		{"forwarded https", "https", http.StatusOK, ""},
+		{"forwarded http", "http", http.StatusPermanentRedirect, "https://example.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://internal:8080/", nil)
+			req.Header.Set("X-Forwarded-Proto", tt.proto)
+			req.Header.Set("X-Forwarded-Host", "example.com")
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.location {
+				t.Errorf("Expected location %s, got %s", tt.location, location)
+			}
+		})
+	}
+
+	untrusted := NewServer().Use(CanonicalHostMiddleware(CanonicalHostConfig{Scheme: "https"}))
+	untrusted.GET("/", func(c *Context) *Response { return Respond().Text("ok") })
+	req := httptest.NewRequest("GET", "http://example.com/", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	untrusted.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusPermanentRedirect {
+		t.Errorf("Expected untrusted forwarding headers to be ignored, got %d", w.Code)
+	}
+}
@@ -5,14 +5,28 @@
 package srv
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"html/template"
 	"io"
 	"log/slog"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -55,34 +69,62 @@ const (
 )
 
 var (
-	ErrNoBody = errors.New("no requestbody")
+	ErrNoBody                 = errors.New("no requestbody")
+	ErrEarlyHintsNotSupported = errors.New("early hints are not supported for HTTP/1.0 clients")
+	ErrNoClaims               = errors.New("no claims header")
 )
 
 type contextConfig struct {
 	maxMultipartMemory int64
+	maxRequestBody     int64
 	ipResolver         *IPResolver
+	errorPages         map[int]*template.Template
+	templates          *template.Template
+	strictSparseFields bool
+	errorMapper        ErrorMapper
+	pageSizeParam      string
 }
 
 // Context represents the context of an HTTP request.
 type Context struct {
-	conf        *contextConfig
-	w           http.ResponseWriter
-	r           *http.Request
-	queryParsed bool
-	query       url.Values
-	formCache   url.Values
-	values      map[string]any
-	ipResolved  bool
-	ipAddresses []string
+	conf           *contextConfig
+	w              http.ResponseWriter
+	r              *http.Request
+	queryParsed    bool
+	query          url.Values
+	formCache      url.Values
+	formErr        error
+	values         map[string]any
+	ipResolved     bool
+	ipAddresses    []string
+	timingsMu      sync.Mutex
+	timings        []serverTiming
+	bodyLimited    bool
+	maxRequestBody int64
+	hijacked       bool
+	pattern        string
+}
+
+func newContextConfig() *contextConfig {
+	return &contextConfig{
+		maxMultipartMemory: DefaultMaxMultipartMemory,
+		errorPages:         make(map[int]*template.Template),
+		pageSizeParam:      PageSizeParam,
+		ipResolver: NewIPResolver([]string{
+			"X-Forwarded-For",
+			"Forwarded",
+		}, false),
+	}
 }
 
 // NewContext creates a new Context with the given http.ResponseWriter and http.Request.
 func NewContext(w http.ResponseWriter, r *http.Request, conf *contextConfig) *Context {
 	return &Context{
-		w:      w,
-		r:      r,
-		values: make(map[string]any),
-		conf:   conf,
+		w:              w,
+		r:              r,
+		values:         make(map[string]any),
+		conf:           conf,
+		maxRequestBody: conf.maxRequestBody,
 	}
 }
 
@@ -91,24 +133,98 @@ func (c *Context) Request() *http.Request {
 	return c.r
 }
 
+// Pattern returns the path pattern of the matched route without the method, e.g. "/users/{id}".
+// Use it instead of the request path to label metrics and logs. If no route matched, e.g. in the
+// not found handler, the request path is returned.
+func (c *Context) Pattern() string {
+	if route := c.route(); route != "" {
+		return route
+	}
+	return c.r.URL.Path
+}
+
+// route returns the path pattern of the matched route or an empty string if no route matched.
+func (c *Context) route() string {
+	if _, path, ok := strings.Cut(c.pattern, " "); ok {
+		return path
+	}
+	return c.pattern
+}
+
 // ClientIP returns the client IP address from the request. When proxies are trusted,
 // the address is resolved from proxy headers like X-Forwarded-For. Otherwise, the
-// direct remote address is used.
+// direct remote address is used. Returns an empty string if the address can't be determined.
 func (c *Context) ClientIP() string {
-	if !c.ipResolved {
-		c.ipAddresses = c.conf.ipResolver.Resolve(c.r)
-		c.ipResolved = true
+	ips := c.resolveIPs()
+	if len(ips) == 0 {
+		return ""
 	}
-	return c.ipAddresses[0]
+	return ips[0]
 }
 
 // RemoteIP returns the remote IP address from the request.
+// Returns an empty string if the remote address is not an IP address, e.g. for unix sockets.
 func (c *Context) RemoteIP() string {
+	ips := c.resolveIPs()
+	if len(ips) == 0 {
+		return ""
+	}
+	return ips[len(ips)-1]
+}
+
+func (c *Context) resolveIPs() []string {
 	if !c.ipResolved {
 		c.ipAddresses = c.conf.ipResolver.Resolve(c.r)
 		c.ipResolved = true
 	}
-	return c.ipAddresses[len(c.ipAddresses)-1]
+	return c.ipAddresses
+}
+
+// RemotePort returns the port of the direct remote address of the request.
+// Returns false if the remote address has no valid port.
+func (c *Context) RemotePort() (int, bool) {
+	_, rawPort, err := net.SplitHostPort(strings.TrimSpace(c.r.RemoteAddr))
+	if err != nil {
+		return 0, false
+	}
+	port, err := strconv.Atoi(rawPort)
+	if err != nil || port < 0 || port > 65535 {
+		return 0, false
+	}
+	return port, true
+}
+
+// LocalAddr returns the local address the request was received on, e.g. "10.0.0.1:8080".
+// Returns an empty string if the address is not available, e.g. in tests.
+func (c *Context) LocalAddr() string {
+	addr, ok := c.r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		return ""
+	}
+	return addr.String()
+}
+
+// ForwardedInfo returns the proxy metadata of the request. When proxies are trusted, the
+// information is parsed from the Forwarded header or the X-Forwarded-* headers. Missing values
+// and untrusted requests fall back to the direct connection details.
+func (c *Context) ForwardedInfo() ForwardedInfo {
+	info := ForwardedInfo{}
+	if c.conf.ipResolver.TrustRemoteIdHeaders {
+		info = parseForwardedInfo(c.r)
+	}
+	if len(info.For) == 0 {
+		info.For = []string{c.RemoteIP()}
+	}
+	if info.Proto == "" {
+		info.Proto = "http"
+		if c.r.TLS != nil {
+			info.Proto = "https"
+		}
+	}
+	if info.Host == "" {
+		info.Host = c.r.Host
+	}
+	return info
 }
 
 // PathValue returns the value of the specified path parameter from the request.
@@ -116,6 +232,36 @@ func (c *Context) PathValue(name string) string {
 	return c.r.PathValue(name)
 }
 
+// UUIDPathValue returns the value of the specified path parameter if it is a UUID in canonical form.
+// Returns a BadRequest response if the value is missing or malformed.
+func (c *Context) UUIDPathValue(name string) (string, *Response) {
+	val := c.PathValue(name)
+	if !UUIDPattern.MatchString(val) {
+		return "", respondError(http.StatusBadRequest, "BadRequest", "invalid value for '"+name+"'")
+	}
+	return val, nil
+}
+
+// IntPathValue returns the value of the specified path parameter as int.
+// Returns a BadRequest response if the value is missing or not an integer.
+func (c *Context) IntPathValue(name string) (int, *Response) {
+	i, err := strconv.Atoi(c.PathValue(name))
+	if err != nil {
+		return 0, respondError(http.StatusBadRequest, "BadRequest", "invalid value for '"+name+"'")
+	}
+	return i, nil
+}
+
+// Int64PathValue returns the value of the specified path parameter as int64.
+// Returns a BadRequest response if the value is missing or not an integer.
+func (c *Context) Int64PathValue(name string) (int64, *Response) {
+	i, err := strconv.ParseInt(c.PathValue(name), 10, 64)
+	if err != nil {
+		return 0, respondError(http.StatusBadRequest, "BadRequest", "invalid value for '"+name+"'")
+	}
+	return i, nil
+}
+
 // HasQuery checks if the request has a query parameter with the given key.
 func (c *Context) HasQuery(key string) bool {
 	if !c.queryParsed {
@@ -144,10 +290,7 @@ func (c *Context) IntQueryOrDefault(key string, defaultValue int) (int, *Respons
 	}
 	i, err := strconv.Atoi(val)
 	if err != nil {
-		return 0, Respond().BadRequest(ErrorDto{
-			Code:    "BadRequest",
-			Message: "invalid value for '" + key + "'",
-		})
+		return 0, respondError(http.StatusBadRequest, "BadRequest", "invalid value for '"+key+"'")
 	}
 	return i, nil
 }
@@ -163,10 +306,7 @@ func (c *Context) StringQueryOrDefault(key string, defaultValue string) (string,
 	}
 	s, err := url.QueryUnescape(val)
 	if err != nil {
-		return "", Respond().BadRequest(ErrorDto{
-			Code:    "BadRequest",
-			Message: "invalid value for '" + key + "'",
-		})
+		return "", respondError(http.StatusBadRequest, "BadRequest", "invalid value for '"+key+"'")
 	}
 	return s, nil
 }
@@ -181,6 +321,65 @@ func (c *Context) Authorization() string {
 	return c.Header("Authorization")
 }
 
+// AuthScheme splits the Authorization header into the authentication scheme and the credentials.
+// Both are empty if the header is absent.
+func (c *Context) AuthScheme() (scheme, credentials string) {
+	scheme, credentials, _ = strings.Cut(strings.TrimSpace(c.Authorization()), " ")
+	return scheme, strings.TrimSpace(credentials)
+}
+
+// BearerToken returns the token of a Bearer Authorization header. The scheme is matched case-insensitively.
+// Returns false if the header is absent, uses another scheme or has no token.
+func (c *Context) BearerToken() (string, bool) {
+	scheme, token := c.AuthScheme()
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// ForwardedClaims decodes the JWT claims an upstream gateway forwards in the given header, e.g. "X-Jwt-Claims",
+// after validating the token. The header may contain the base64url or base64 encoded JSON claims or the
+// complete token, whose payload is decoded. The signature is not verified, so only use it if the gateway
+// removes the header from client requests. Returns ErrNoClaims if the header is missing.
+func (c *Context) ForwardedClaims(header string) (map[string]any, error) {
+	raw := strings.TrimSpace(c.Header(header))
+	if raw == "" {
+		return nil, ErrNoClaims
+	}
+	if parts := strings.Split(raw, "."); len(parts) == 3 {
+		raw = parts[1]
+	}
+	raw = strings.TrimRight(raw, "=")
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		if b, err = base64.RawStdEncoding.DecodeString(raw); err != nil {
+			return nil, err
+		}
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// RetryAttempt returns the retry count a cooperative client sends in the X-Retry-Attempt header,
+// e.g. to apply progressive backoff. Returns 0 if the header is absent or not a non-negative integer.
+func (c *Context) RetryAttempt() int {
+	n, err := strconv.Atoi(strings.TrimSpace(c.Header("X-Retry-Attempt")))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// BasicAuth returns the username and password provided in the request's Authorization header,
+// if the request uses HTTP Basic Authentication.
+func (c *Context) BasicAuth() (username, password string, ok bool) {
+	return c.r.BasicAuth()
+}
+
 // ProxyAuthorization returns the value of the Proxy-Authorization header.
 func (c *Context) ProxyAuthorization() string {
 	return c.Header("Proxy-Authorization")
@@ -242,16 +441,56 @@ func (c *Context) Accept() string {
 	return c.Header("Accept")
 }
 
+// AcceptCharset returns the value of the Accept-Charset header.
+func (c *Context) AcceptCharset() string {
+	return c.Header("Accept-Charset")
+}
+
+// PreferredCharset returns the charset from supported that the client prefers most according
+// to the q-values in the Accept-Charset header. If the header is absent, the first supported
+// charset is returned. If none of the supported charsets is acceptable, an empty string is returned.
+func (c *Context) PreferredCharset(supported ...string) string {
+	return negotiate(c.AcceptCharset(), supported)
+}
+
 // AcceptEncoding returns the value of the Accept-Encoding header.
 func (c *Context) AcceptEncoding() string {
 	return c.Header("Accept-Encoding")
 }
 
+// NegotiateEncoding returns the content coding from supported that the client prefers most according
+// to the q-values in the Accept-Encoding header, e.g. "br" or "gzip". ContentEncodingIdentity is returned
+// if the response should not be encoded, which is the case when the header is absent. If neither a supported
+// coding nor identity is acceptable, e.g. with "identity;q=0", an empty string is returned.
+func (c *Context) NegotiateEncoding(supported ...string) string {
+	return negotiateEncoding(c.AcceptEncoding(), supported)
+}
+
 // AcceptLanguage returns the value of the Accept-Language header.
 func (c *Context) AcceptLanguage() string {
 	return c.Header("Accept-Language")
 }
 
+// PreferredLanguage returns the language tag from supported that the client prefers most according to the
+// q-values in the Accept-Language header. Ranges match tags exactly or as a prefix, e.g. "en" matches "en-US",
+// and a supported tag that is a prefix of a range is used as fallback, e.g. "en-US" matches "en".
+// If the header is absent, the first supported tag is returned. If no tag matches, an empty string is returned.
+func (c *Context) PreferredLanguage(supported ...string) string {
+	return negotiateLanguage(c.AcceptLanguage(), supported)
+}
+
+// Languages returns the language tags requested in the Accept-Language header, ordered by descending
+// q-value. The wildcard "*" and tags with q=0 are omitted.
+func (c *Context) Languages() []string {
+	languages := make([]string, 0)
+	for _, spec := range parseAccept(c.AcceptLanguage()) {
+		if spec.q > 0 && spec.value != "*" {
+			languages = append(languages, spec.raw)
+		}
+	}
+	return languages
+}
+
 // Expect returns the value of the Expect header.
 func (c *Context) Expect() string {
 	return c.Header("Expect")
@@ -429,6 +668,55 @@ func (c *Context) SecPurpose() string {
 	return c.Header("Sec-Purpose")
 }
 
+// IsPrefetch returns true if the request is a speculative prefetch or prerender, as indicated
+// by the Sec-Purpose header (or the legacy Purpose header). Handlers can use this to skip side effects.
+func (c *Context) IsPrefetch() bool {
+	purpose := c.SecPurpose()
+	if purpose == "" {
+		purpose = c.Header("Purpose")
+	}
+	token, _, _ := strings.Cut(purpose, ";")
+	return strings.TrimSpace(token) == SecPurposePrefetch
+}
+
+// VerifyOrigin returns true if the request uses a safe method or if its Origin header, or the origin of the
+// Referer header if Origin is missing, is one of allowedOrigins, e.g. "https://example.com". Without
+// allowedOrigins, the origin must match the request's own scheme and host. Requests with unsafe methods
+// that carry neither header are rejected.
+func (c *Context) VerifyOrigin(allowedOrigins ...string) bool {
+	switch c.r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	origin := c.Header("Origin")
+	if origin == "" {
+		ref, err := url.Parse(c.Header("Referer"))
+		if err != nil || ref.Scheme == "" || ref.Host == "" {
+			return false
+		}
+		origin = ref.Scheme + "://" + ref.Host
+	}
+	return c.originAllowed(origin, allowedOrigins)
+}
+
+// originAllowed reports whether origin is one of allowedOrigins or, without allowedOrigins, matches the
+// request's own scheme and host.
+func (c *Context) originAllowed(origin string, allowedOrigins []string) bool {
+	if len(allowedOrigins) == 0 {
+		scheme := "http"
+		if c.r.TLS != nil {
+			scheme = "https"
+		}
+		allowedOrigins = []string{scheme + "://" + c.r.Host}
+	}
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
 // ServiceWorkerNavigationPreload returns the value of the Service-Worker-Navigation-Preload header.
 func (c *Context) ServiceWorkerNavigationPreload() string {
 	return c.Header("Service-Worker-Navigation-Preload")
@@ -449,6 +737,13 @@ func (c *Context) Trailer() string {
 	return c.Header("Trailer")
 }
 
+// Trailers returns the trailer fields sent after the request body, e.g. a checksum of a chunked upload.
+// The values are only available after the body has been read completely, e.g. with Body or BindJSON.
+// Before that, the announced trailer names may map to nil values. Returns nil if no trailers were announced.
+func (c *Context) Trailers() http.Header {
+	return c.r.Trailer
+}
+
 // Date returns the value of the Date header.
 func (c *Context) Date() (time.Time, bool) {
 	raw := c.Header("Date")
@@ -472,23 +767,53 @@ func (c *Context) ServiceWorker() bool {
 	return c.Header("Service-Worker") == "script"
 }
 
+// EarlyHints sends a 103 Early Hints interim response with the given Link header values,
+// e.g. `</style.css>; rel=preload; as=style`. It must be called before the final response is written.
+// The Link headers are kept and sent with the final response as well.
+// Returns ErrEarlyHintsNotSupported for HTTP/1.0 clients, which must not receive interim responses.
+func (c *Context) EarlyHints(links ...string) error {
+	if !c.r.ProtoAtLeast(1, 1) {
+		return ErrEarlyHintsNotSupported
+	}
+	for _, link := range links {
+		c.w.Header().Add("Link", link)
+	}
+	c.w.WriteHeader(http.StatusEarlyHints)
+	return nil
+}
+
+// Flush sends headers and data written to the underlying http.ResponseWriter so far to the client,
+// e.g. after EarlyHints. Flushing commits the response headers, so the status code of the Response
+// returned by the handler can no longer be sent; use it for responses written outside of the Response,
+// e.g. long polling. Returns an error wrapping http.ErrNotSupported if the writer can't flush.
+func (c *Context) Flush() error {
+	return http.NewResponseController(c.w).Flush()
+}
+
 // ConditionalIfMatch makes the request conditional. Returns a response when the precondition fails.
+// The If-Match header may contain a list of ETags; the precondition passes if any of them matches
+// using the strong comparison, i.e. weak validators never match. A localEtag prefixed with "W/" denotes
+// a weak validator. An empty localEtag signals that the resource does not exist, so that "If-Match: *"
+// only passes for existing resources.
 func (c *Context) ConditionalIfMatch(localEtag string) *Response {
 	remoteEtag := c.r.Header.Get("If-Match")
-	if remoteEtag == "" || "\""+localEtag+"\"" == remoteEtag {
+	if remoteEtag == "" || matchETag(remoteEtag, localEtag, false) {
 		return nil
 	}
 	return Respond().PreconditionFailed()
 }
 
 // ConditionalIfNoneMatch makes the request conditional. Returns a response when the precondition fails.
+// The If-None-Match header may contain a list of ETags; the precondition fails if any of them matches
+// using the weak comparison, i.e. ignoring the "W/" prefix. A localEtag prefixed with "W/" denotes a weak
+// validator. "If-None-Match: *" fails for existing resources, i.e. if localEtag is not empty.
 func (c *Context) ConditionalIfNoneMatch(localEtag string) *Response {
 	remoteEtag := c.r.Header.Get("If-None-Match")
-	if remoteEtag == "" || "\""+localEtag+"\"" != remoteEtag {
+	if remoteEtag == "" || !matchETag(remoteEtag, localEtag, true) {
 		return nil
 	}
 	if c.r.Method == http.MethodGet || c.r.Method == http.MethodHead {
-		return Respond().NotModified().ETag(localEtag)
+		return Respond().NotModified().localETag(localEtag)
 	}
 	return Respond().PreconditionFailed()
 }
@@ -497,10 +822,7 @@ func (c *Context) ConditionalIfNoneMatch(localEtag string) *Response {
 func (c *Context) ConditionalIfModifiedSince(lastModified ...time.Time) *Response {
 	t, ok, err := c.IfModifiedSince()
 	if err != nil {
-		return Respond().BadRequest(ErrorDto{
-			Code:    "BadRequest",
-			Message: "invalid value for 'If-Modified-Since'",
-		})
+		return respondError(http.StatusBadRequest, "BadRequest", "invalid value for 'If-Modified-Since'")
 	}
 	if !ok {
 		return nil
@@ -512,11 +834,51 @@ func (c *Context) ConditionalIfModifiedSince(lastModified ...time.Time) *Respons
 	return Respond().NotModified().LastModified(lm)
 }
 
-// BindJSON tries to bind a json payload. Returns a response if the binding was unsuccessful
+// CheckPreconditions evaluates the conditional request headers against the current etag and lastModified
+// of the resource in the order defined by RFC 9110: If-Match, If-Unmodified-Since, If-None-Match and
+// If-Modified-Since. The date based headers are ignored if the corresponding ETag header is present, if
+// they are invalid or if lastModified is zero. An etag prefixed with "W/" denotes a weak validator, which
+// never satisfies If-Match but may satisfy If-None-Match. Returns 304 Not Modified or 412 Precondition
+// Failed if a precondition fails and nil if the request should proceed.
+func (c *Context) CheckPreconditions(etag string, lastModified time.Time) *Response {
+	lm := lastModified.Truncate(time.Second)
+	if c.r.Header.Get("If-Match") != "" {
+		if res := c.ConditionalIfMatch(etag); res != nil {
+			return res
+		}
+	} else if t, ok, err := c.IfUnmodifiedSince(); ok && err == nil && !lastModified.IsZero() && lm.After(t) {
+		return Respond().PreconditionFailed()
+	}
+
+	if c.r.Header.Get("If-None-Match") != "" {
+		if res := c.ConditionalIfNoneMatch(etag); res != nil {
+			if res.StatusCode == http.StatusNotModified && !lastModified.IsZero() {
+				res.LastModified(lm)
+			}
+			return res
+		}
+		return nil
+	}
+	if c.r.Method != http.MethodGet && c.r.Method != http.MethodHead {
+		return nil
+	}
+	if t, ok, err := c.IfModifiedSince(); ok && err == nil && !lastModified.IsZero() && !lm.After(t) {
+		res := Respond().NotModified().LastModified(lm)
+		if etag != "" {
+			res.localETag(etag)
+		}
+		return res
+	}
+	return nil
+}
+
+// BindJSON tries to bind a json payload. Returns a response if the binding was unsuccessful.
+// The data is validated using its "validate" struct tags (see ValidateStruct) and, if it implements
+// Validatable, its Validate method.
 func (c *Context) BindJSON(data any) *Response {
-	b, err := io.ReadAll(c.r.Body)
+	b, err := io.ReadAll(c.body())
 	if err != nil {
-		return respondInternalServerError(err)
+		return respondReadError(err)
 	}
 	if len(b) == 0 {
 		return respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
@@ -524,19 +886,228 @@ func (c *Context) BindJSON(data any) *Response {
 	if err := json.Unmarshal(b, data); err != nil {
 		return respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
 	}
-	v, ok := data.(Validatable)
-	if ok {
-		if err := v.Validate(); err != nil {
+	return validate(data)
+}
+
+// BindJSONStrict works like BindJSON, but rejects payloads with fields that don't exist in data.
+// The body is decoded while it is read instead of being buffered first.
+func (c *Context) BindJSONStrict(data any) *Response {
+	dec := json.NewDecoder(c.body())
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
+		}
+		return respondBodyError(err)
+	}
+	if dec.More() {
+		return respondError(http.StatusBadRequest, "InvalidRequestBody", "request body must contain a single JSON value")
+	}
+	return validate(data)
+}
+
+// BindJSONMap decodes a JSON object from the request body into a map, e.g. for dynamic payloads.
+// Numbers are decoded as json.Number to preserve their precision. Returns a response if the body
+// is missing or not a JSON object.
+func (c *Context) BindJSONMap() (map[string]any, *Response) {
+	dec := json.NewDecoder(c.body())
+	dec.UseNumber()
+	var data map[string]any
+	if err := dec.Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
+		}
+		return nil, respondBodyError(err)
+	}
+	if data == nil {
+		return nil, respondError(http.StatusBadRequest, "InvalidRequestBody", "request body must be a JSON object")
+	}
+	if dec.More() {
+		return nil, respondError(http.StatusBadRequest, "InvalidRequestBody", "request body must contain a single JSON value")
+	}
+	return data, nil
+}
+
+// BindMultipart binds the non-file fields of a multipart/form-data request into data and returns the
+// uploaded files by field name. Fields are matched by their "form" struct tag (see bindValues) and
+// data is validated like in BindJSON. Returns a response if the binding was unsuccessful, e.g. 500 Internal
+// Server Error if data is not a pointer to a struct.
+func (c *Context) BindMultipart(data any) (map[string][]*multipart.FileHeader, *Response) {
+	if mediaType(c.ContentType()) != "multipart/form-data" {
+		return nil, respondError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "content type must be multipart/form-data")
+	}
+	if !isFormTarget(data) {
+		return nil, respondInvalidFormTarget(data)
+	}
+	c.body()
+	if err := c.r.ParseMultipartForm(c.conf.maxMultipartMemory); err != nil {
+		return nil, respondBodyError(err)
+	}
+	if err := bindValues(c.r.MultipartForm.Value, data); err != nil {
+		return nil, respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
+	}
+	if res := validate(data); res != nil {
+		return nil, res
+	}
+	return c.r.MultipartForm.File, nil
+}
+
+// Bind binds the request body into data depending on the Content-Type of the request. JSON payloads are
+// bound with BindJSON, XML payloads with BindXML and forms with BindForm, so data is validated regardless
+// of the format. Returns 415 Unsupported Media Type for other content types. Forms can only be bound into
+// a pointer to a struct; other targets, e.g. a map, result in 500 Internal Server Error.
+func (c *Context) Bind(data any) *Response {
+	mt := mediaType(c.ContentType())
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return c.BindJSON(data)
+	case mt == "application/xml" || mt == "text/xml" || strings.HasSuffix(mt, "+xml"):
+		return c.BindXML(data)
+	case mt == "application/x-www-form-urlencoded" || mt == "multipart/form-data":
+		return c.BindForm(data)
+	}
+	return respondError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "unsupported content type")
+}
+
+// BindXML tries to bind an XML payload. Returns a response if the binding was unsuccessful.
+// data is validated like in BindJSON.
+func (c *Context) BindXML(data any) *Response {
+	b, err := io.ReadAll(c.body())
+	if err != nil {
+		return respondReadError(err)
+	}
+	if len(b) == 0 {
+		return respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
+	}
+	if err := xml.Unmarshal(b, data); err != nil {
+		return respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
+	}
+	return validate(data)
+}
+
+// BindForm binds an urlencoded or multipart form into data. Fields are matched by their "form" struct
+// tag (see bindValues) and data is validated like in BindJSON. Query parameters are not bound.
+// Returns a response if the binding was unsuccessful, e.g. 500 Internal Server Error if data is not a
+// pointer to a struct, since forms can't be bound into other types.
+func (c *Context) BindForm(data any) *Response {
+	mt := mediaType(c.ContentType())
+	if mt != "application/x-www-form-urlencoded" && mt != "multipart/form-data" {
+		return respondError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "content type must be application/x-www-form-urlencoded or multipart/form-data")
+	}
+	if !isFormTarget(data) {
+		return respondInvalidFormTarget(data)
+	}
+	if res := c.ParseForm(); res != nil {
+		return res
+	}
+	if err := bindValues(c.formCache, data); err != nil {
+		return respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
+	}
+	return validate(data)
+}
+
+// BindMergePatch applies a JSON Merge Patch (RFC 7386) from the request body to current and returns the
+// patched representation as a new value of the same type as current. current itself is not modified.
+// Members set to null in the patch are removed. The request must have the Content-Type
+// application/merge-patch+json. The result is validated like in BindJSON. current must be a struct or a
+// non-nil pointer to a struct; other values result in 500 Internal Server Error.
+func (c *Context) BindMergePatch(current any) (any, *Response) {
+	v := reflect.ValueOf(current)
+	isPointer := v.Kind() == reflect.Pointer
+	if isPointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		slog.Error("invalid merge patch target", "type", fmt.Sprintf("%T", current))
+		return nil, respondError(http.StatusInternalServerError, "InternalServerError", "unable to apply patch")
+	}
+	if mediaType(c.ContentType()) != "application/merge-patch+json" {
+		return nil, respondError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "content type must be application/merge-patch+json")
+	}
+	patch, err := io.ReadAll(c.body())
+	if err != nil {
+		return nil, respondReadError(err)
+	}
+	if len(patch) == 0 {
+		return nil, respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
+	}
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, respondInternalServerError(err)
+	}
+	merged, err := applyMergePatch(doc, patch)
+	if err != nil {
+		return nil, respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
+	}
+	result := reflect.New(v.Type())
+	if err := json.Unmarshal(merged, result.Interface()); err != nil {
+		return nil, respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
+	}
+	if res := validate(result.Interface()); res != nil {
+		return nil, res
+	}
+	if isPointer {
+		return result.Interface(), nil
+	}
+	return result.Elem().Interface(), nil
+}
+
+// StreamJSONArray decodes a top-level JSON array from the request body element by element,
+// without reading the whole array into memory. fn is called once per element and receives a
+// function that decodes the current element into the given value. Elements that fn doesn't decode
+// are skipped. Returns a BadRequest response if the body is not a well-formed JSON array or fn returns
+// a *ValidationError, and an InternalServerError response if fn returns any other error.
+func (c *Context) StreamJSONArray(fn func(decode func(any) error) error) *Response {
+	if c.r.Body == nil {
+		return respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
+	}
+	dec := json.NewDecoder(c.body())
+	t, err := dec.Token()
+	if err == io.EOF {
+		return respondError(http.StatusBadRequest, "RequestBodyMissing", "request body is missing")
+	}
+	if err != nil {
+		return respondBodyError(err)
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		return respondError(http.StatusBadRequest, "InvalidRequestBody", "request body must be a JSON array")
+	}
+	for dec.More() {
+		decoded := false
+		var decodeErr error
+		decode := func(v any) error {
+			decoded = true
+			decodeErr = dec.Decode(v)
+			return decodeErr
+		}
+		if err := fn(decode); err != nil {
 			if v, ok := err.(*ValidationError); ok {
 				return Respond().BadRequest(v)
 			}
-			return respondError(http.StatusBadRequest, "BadRequest", err.Error())
+			if decodeErr != nil {
+				return respondBodyError(decodeErr)
+			}
+			return respondInternalServerError(err)
+		}
+		if decodeErr != nil {
+			return respondBodyError(decodeErr)
+		}
+		if !decoded {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return respondBodyError(err)
+			}
 		}
 	}
+	if _, err := dec.Token(); err != nil {
+		return respondBodyError(err)
+	}
 	return nil
 }
 
-// FormValues returns the values from a POST urlencoded form or multipart form
+// FormValues returns the values from a POST urlencoded form or multipart form. If the form can't be
+// parsed, e.g. because the body exceeds the request body limit, the values parsed so far are returned;
+// use ParseForm to detect this.
 func (c *Context) FormValues() url.Values {
 	if c.formCache == nil {
 		c.parseForm()
@@ -544,14 +1115,94 @@ func (c *Context) FormValues() url.Values {
 	return c.formCache
 }
 
+// ParseForm parses a POST urlencoded form or multipart form, like FormValues, FormFile and FormFiles do
+// lazily. Returns 413 Request Entity Too Large if the body exceeds the request body limit and 400 Bad
+// Request if the form is malformed, so that handlers apply the limit like BindForm does.
+func (c *Context) ParseForm() *Response {
+	if c.formCache == nil {
+		c.parseForm()
+	}
+	if c.formErr != nil {
+		return respondBodyError(c.formErr)
+	}
+	return nil
+}
+
 func (c *Context) parseForm() {
 	c.formCache = make(url.Values)
-	if err := c.r.ParseMultipartForm(c.conf.maxMultipartMemory); err != nil {
-		if !errors.Is(err, http.ErrNotMultipart) {
-			slog.Error("unable to parse multipart form", "error", err)
-		}
+	c.body()
+	// ParseMultipartForm drops errors of urlencoded forms, e.g. exceeding the body limit.
+	if err := c.r.ParseForm(); err != nil {
+		c.formErr = err
+	} else if err := c.r.ParseMultipartForm(c.conf.maxMultipartMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		c.formErr = err
+	}
+	if c.r.PostForm != nil {
+		c.formCache = c.r.PostForm
+	}
+}
+
+// MultipartReader returns a streaming reader for the parts of a multipart/form-data or multipart/mixed
+// request, e.g. to pipe large uploads directly to storage. Unlike FormValues and FormFile, the body is not
+// buffered, so they must not be used together. Returns http.ErrNotMultipart if the request is not multipart.
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	c.body()
+	return c.r.MultipartReader()
+}
+
+// FormFile returns the first file uploaded in the multipart form field name. The form is parsed lazily
+// like in FormValues. Returns the parse error if the form can't be parsed, e.g. an *http.MaxBytesError if
+// the body exceeds the request body limit, http.ErrNotMultipart if the request is not a multipart form and
+// http.ErrMissingFile if no file was uploaded in the field.
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	if c.formCache == nil {
+		c.parseForm()
+	}
+	if c.formErr != nil {
+		return nil, c.formErr
+	}
+	if c.r.MultipartForm == nil {
+		return nil, http.ErrNotMultipart
+	}
+	files := c.r.MultipartForm.File[name]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return files[0], nil
+}
+
+// FormFiles returns all files uploaded in the multipart form field name.
+// Returns nil if the request is not a multipart form or no file was uploaded in the field.
+func (c *Context) FormFiles(name string) []*multipart.FileHeader {
+	if c.formCache == nil {
+		c.parseForm()
+	}
+	if c.r.MultipartForm == nil {
+		return nil
+	}
+	return c.r.MultipartForm.File[name]
+}
+
+// SaveUploadedFile writes the content of file to dst. Missing parent directories are created.
+// Never use the client provided file.Filename in dst without sanitizing it.
+func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0750); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		return err
 	}
-	c.formCache = c.r.PostForm
+	return out.Close()
 }
 
 // HxBoosted returns true if the request is an HX-Boosted request.
@@ -600,7 +1251,101 @@ func (c *Context) GetRawData() ([]byte, error) {
 	if c.r.Body == nil {
 		return nil, ErrNoBody
 	}
-	return io.ReadAll(c.r.Body)
+	return io.ReadAll(c.body())
+}
+
+// Body reads the request body and returns the raw data. Unlike GetRawData, the body is buffered,
+// so it can be read again afterwards, e.g. by BindJSON.
+// Returns ErrNoBody if the request body is nil.
+func (c *Context) Body() ([]byte, error) {
+	return c.bufferBody()
+}
+
+// body returns the request body limited to the configured maximum request body size.
+// Reading beyond the limit fails with an *http.MaxBytesError.
+func (c *Context) body() io.ReadCloser {
+	if c.r.Body == nil {
+		c.r.Body = http.NoBody
+	}
+	if !c.bodyLimited && c.maxRequestBody > 0 {
+		c.r.Body = http.MaxBytesReader(c.w, c.r.Body, c.maxRequestBody)
+	}
+	c.bodyLimited = true
+	return c.r.Body
+}
+
+// VerifySignature checks the HMAC-SHA256 signature in the given header against the request body.
+// The signature must be hex encoded and may be prefixed with "sha256=". Returns false if the
+// header is missing or the signature doesn't match. The body is buffered, so it can be read again afterwards.
+func (c *Context) VerifySignature(header string, key []byte) (bool, error) {
+	raw := c.Header(header)
+	if raw == "" {
+		return false, nil
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(raw, signaturePrefix))
+	if err != nil {
+		return false, err
+	}
+	body, err := c.bufferBody()
+	if err != nil {
+		return false, err
+	}
+	return hmac.Equal(sig, computeHMAC(key, body)), nil
+}
+
+// VerifyContentDigest checks the request body against the digests in the Content-Digest header,
+// falling back to the legacy Digest header. Returns false if a digest doesn't match, ErrNoDigest if
+// neither header is present and ErrUnsupportedDigest if no digest uses sha-256 or sha-512.
+// The body is buffered, so it can be read again afterwards.
+func (c *Context) VerifyContentDigest() (bool, error) {
+	header := c.Header("Content-Digest")
+	if header == "" {
+		header = c.Header("Digest")
+	}
+	if header == "" {
+		return false, ErrNoDigest
+	}
+	body, err := c.bufferBody()
+	if err != nil {
+		return false, err
+	}
+	return verifyDigests(header, body)
+}
+
+// bufferBody reads the request body and replaces it with an in-memory copy,
+// so that subsequent reads return the same data.
+func (c *Context) bufferBody() ([]byte, error) {
+	if c.r.Body == nil {
+		return nil, ErrNoBody
+	}
+	b, err := io.ReadAll(c.body())
+	if err != nil {
+		return nil, err
+	}
+	c.r.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// Timing starts measuring a sub-timing with the given name and returns a function that stops it.
+// Recorded timings are sent in the Server-Timing header by ServerTimingMiddleware. Timings may be
+// recorded from multiple goroutines, e.g. for queries running in parallel.
+//
+//	defer c.Timing("db")()
+func (c *Context) Timing(name string) func() {
+	start := time.Now()
+	return func() {
+		t := serverTiming{name: name, duration: time.Since(start)}
+		c.timingsMu.Lock()
+		defer c.timingsMu.Unlock()
+		c.timings = append(c.timings, t)
+	}
+}
+
+// serverTimings returns a copy of the timings recorded with Timing.
+func (c *Context) serverTimings() []serverTiming {
+	c.timingsMu.Lock()
+	defer c.timingsMu.Unlock()
+	return slices.Clone(c.timings)
 }
 
 func (c *Context) Set(key string, value any) {
@@ -620,6 +1365,23 @@ func (c *Context) MustGet(key string) any {
 	return v
 }
 
+// GetAs returns the value stored under key in the context if it has type T.
+func GetAs[T any](c *Context, key string) (T, bool) {
+	v, ok := c.values[key].(T)
+	return v, ok
+}
+
+// MustGetAs returns the value stored under key in the context.
+// It panics if the key doesn't exist or the value doesn't have type T.
+func MustGetAs[T any](c *Context, key string) T {
+	v := c.MustGet(key)
+	t, ok := v.(T)
+	if !ok {
+		panic(fmt.Sprintf("value of key '%s' in context has type %T, not %s", key, v, reflect.TypeFor[T]()))
+	}
+	return t
+}
+
 func (c *Context) Deadline() (time.Time, bool) {
 	return c.r.Context().Deadline()
 }
@@ -636,13 +1398,97 @@ func (c *Context) Value(key any) any {
 	return c.r.Context().Value(key)
 }
 
+// BindJSONPatch reads a JSON Patch (RFC 6902) document from the request body. The request must have the
+// Content-Type application/json-patch+json. Returns a BadRequest response if an operation is malformed.
+// Use ApplyJSONPatch to apply the patch to the current representation.
+func (c *Context) BindJSONPatch() (JSONPatch, *Response) {
+	if mediaType(c.ContentType()) != "application/json-patch+json" {
+		return nil, respondError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "content type must be application/json-patch+json")
+	}
+	var patch JSONPatch
+	if res := c.BindJSON(&patch); res != nil {
+		return nil, res
+	}
+	for _, op := range patch {
+		if err := op.Validate(); err != nil {
+			return nil, respondError(http.StatusBadRequest, "InvalidPatch", err.Error())
+		}
+	}
+	return patch, nil
+}
+
+// validate validates data using its "validate" struct tags and, if it implements Validatable,
+// its Validate method. Returns a BadRequest response if data is invalid. Unknown rules are skipped,
+// while invalid tags are logged and result in 500 Internal Server Error.
+func validate(data any) *Response {
+	verr, err := validateTags(data, false)
+	if err != nil {
+		slog.Error("invalid validate tag", "error", err)
+		return respondError(http.StatusInternalServerError, "InternalServerError", "unable to validate request")
+	}
+	if verr != nil {
+		return Respond().BadRequest(verr)
+	}
+	v, ok := data.(Validatable)
+	if ok {
+		if err := v.Validate(); err != nil {
+			if v, ok := err.(*ValidationError); ok {
+				return Respond().BadRequest(v)
+			}
+			return respondError(http.StatusBadRequest, "BadRequest", err.Error())
+		}
+	}
+	return nil
+}
+
+// mediaType returns the lower-cased media type of a Content-Type header without parameters.
+func mediaType(contentType string) string {
+	mt, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// respondBodyError responds with 413 Request Entity Too Large if err was caused by exceeding the
+// request body limit and with 400 Bad Request otherwise.
+func respondBodyError(err error) *Response {
+	if res := respondTooLarge(err); res != nil {
+		return res
+	}
+	return respondError(http.StatusBadRequest, "InvalidRequestBody", err.Error())
+}
+
+// respondReadError responds with 413 Request Entity Too Large if err was caused by exceeding the
+// request body limit and with 500 Internal Server Error otherwise.
+func respondReadError(err error) *Response {
+	if res := respondTooLarge(err); res != nil {
+		return res
+	}
+	return respondInternalServerError(err)
+}
+
+func respondTooLarge(err error) *Response {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return nil
+	}
+	return respondError(http.StatusRequestEntityTooLarge, "RequestBodyTooLarge", tooLargeMessage(maxBytesErr.Limit))
+}
+
+func tooLargeMessage(limit int64) string {
+	return "request body must not exceed " + strconv.FormatInt(limit, 10) + " bytes"
+}
+
+// respondInvalidFormTarget logs that form data can't be bound into data, which is a programming error,
+// and creates a 500 Internal Server Error response.
+func respondInvalidFormTarget(data any) *Response {
+	slog.Error("invalid form binding target", "type", fmt.Sprintf("%T", data))
+	return respondError(http.StatusInternalServerError, "InternalServerError", "unable to bind request")
+}
+
 func respondInternalServerError(err error) *Response {
 	return respondError(http.StatusInternalServerError, "InternalServerError", err.Error())
 }
 
+// respondError creates an error response using the configured ErrorResponder.
 func respondError(statusCode int, code, message string) *Response {
-	return Respond().Status(statusCode).Json(ErrorDto{
-		Code:    code,
-		Message: message,
-	})
+	return errorResponder(statusCode, code, message)
 }
@@ -0,0 +1,1038 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestContext(r *http.Request) (*Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	return NewContext(w, r, NewServer().contextConfig), w
+}
+
+func TestContext_PreferredCharset(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Charset", "iso-8859-1;q=0.5, utf-8;q=0.9, *;q=0.1")
+	c, _ := newTestContext(req)
+
+	if got := c.PreferredCharset("iso-8859-1", "utf-8"); got != "utf-8" {
+		t.Errorf("Expected charset utf-8, got %s", got)
+	}
+	if got := c.PreferredCharset("utf-16"); got != "utf-16" {
+		t.Errorf("Expected wildcard to match utf-16, got %s", got)
+	}
+}
+
+func TestContext_PreferredCharset_NoHeader(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+
+	if got := c.PreferredCharset("utf-8", "iso-8859-1"); got != "utf-8" {
+		t.Errorf("Expected first supported charset, got %s", got)
+	}
+}
+
+func TestContext_EarlyHints(t *testing.T) {
+	s := NewServer()
+	s.GET("/", func(c *Context) *Response {
+		if err := c.EarlyHints("</style.css>; rel=preload; as=style"); err != nil {
+			return Respond().Error(err)
+		}
+		return Respond().Html("<html></html>")
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	var statuses []int
+	var hintLink string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			statuses = append(statuses, code)
+			hintLink = header.Get("Link")
+			return nil
+		},
+	}
+	req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), "GET", ts.URL, nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	statuses = append(statuses, res.StatusCode)
+
+	if len(statuses) != 2 || statuses[0] != http.StatusEarlyHints || statuses[1] != http.StatusOK {
+		t.Errorf("Expected statuses [103 200], got %v", statuses)
+	}
+	if hintLink != "</style.css>; rel=preload; as=style" {
+		t.Errorf("Expected Link header in early hints, got %s", hintLink)
+	}
+}
+
+func TestContext_ForwardedInfo(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	req.Header.Set("Forwarded", `for=192.0.2.60;proto=HTTPS;host=example.com;by=203.0.113.43, for="[2001:db8::1]"`)
+	c, _ := newTestContext(req)
+	c.conf.ipResolver.TrustRemoteIdHeaders = true
+
+	info := c.ForwardedInfo()
+
+	if len(info.For) != 2 || info.For[0] != "192.0.2.60" || info.For[1] != "[2001:db8::1]" {
+		t.Errorf("Expected for chain [192.0.2.60 [2001:db8::1]], got %v", info.For)
+	}
+	if len(info.By) != 1 || info.By[0] != "203.0.113.43" {
+		t.Errorf("Expected by [203.0.113.43], got %v", info.By)
+	}
+	if info.Proto != "https" {
+		t.Errorf("Expected proto https, got %s", info.Proto)
+	}
+	if info.Host != "example.com" {
+		t.Errorf("Expected host example.com, got %s", info.Host)
+	}
+}
+
+func TestContext_ClientIP_UnparseableRemoteAddr(t *testing.T) {
+	for _, trusted := range []bool{false, true} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "@"
+		c, _ := newTestContext(req)
+		c.conf.ipResolver = NewIPResolver([]string{"X-Forwarded-For"}, trusted)
+
+		if ip := c.ClientIP(); ip != "" {
+			t.Errorf("Expected empty client IP, got %s", ip)
+		}
+		if ip := c.RemoteIP(); ip != "" {
+			t.Errorf("Expected empty remote IP, got %s", ip)
+		}
+	}
+}
+
+func TestContext_ClientIP_EmptyResolution(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+	c.ipAddresses = []string{}
+	c.ipResolved = true
+
+	if ip := c.ClientIP(); ip != "" {
+		t.Errorf("Expected empty client IP, got %s", ip)
+	}
+	if ip := c.RemoteIP(); ip != "" {
+		t.Errorf("Expected empty remote IP, got %s", ip)
+	}
+}
+
+func TestContext_ForwardedInfo_NotTrusted(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://internal/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	req.Header.Set("Forwarded", "for=192.0.2.60;proto=https;host=example.com")
+	c, _ := newTestContext(req)
+
+	info := c.ForwardedInfo()
+
+	if len(info.For) != 1 || info.For[0] != "192.168.1.1" {
+		t.Errorf("Expected for [192.168.1.1], got %v", info.For)
+	}
+	if info.Proto != "http" || info.Host != "internal" {
+		t.Errorf("Expected http://internal, got %s://%s", info.Proto, info.Host)
+	}
+}
+
+func TestContext_ConditionalIfMatch_Wildcard(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/", nil)
+	req.Header.Set("If-Match", "*")
+	c, _ := newTestContext(req)
+
+	if res := c.ConditionalIfMatch("abc"); res != nil {
+		t.Errorf("Expected precondition to pass for existing resource, got %d", res.StatusCode)
+	}
+	res := c.ConditionalIfMatch("")
+	if res == nil || res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for non-existing resource, got %v", res)
+	}
+}
+
+func TestContext_ConditionalIfMatch_List(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/", nil)
+	req.Header.Set("If-Match", `"xyz", "abc"`)
+	c, _ := newTestContext(req)
+
+	if res := c.ConditionalIfMatch("abc"); res != nil {
+		t.Errorf("Expected precondition to pass, got %d", res.StatusCode)
+	}
+	res := c.ConditionalIfMatch("def")
+	if res == nil || res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412, got %v", res)
+	}
+}
+
+func TestContext_ConditionalIfNoneMatch_List(t *testing.T) {
+	tests := []struct {
+		method string
+		header string
+		etag   string
+		status int
+	}{
+		{"GET", `"xyz", "abc"`, "abc", http.StatusNotModified},
+		{"GET", `"xyz", "abc"`, "def", 0},
+		{"GET", "*", "abc", http.StatusNotModified},
+		{"PUT", "*", "abc", http.StatusPreconditionFailed},
+		{"PUT", "*", "", 0},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/", nil)
+		req.Header.Set("If-None-Match", tt.header)
+		c, _ := newTestContext(req)
+
+		res := c.ConditionalIfNoneMatch(tt.etag)
+		if tt.status == 0 {
+			if res != nil {
+				t.Errorf("%s %s: Expected no response, got %d", tt.method, tt.header, res.StatusCode)
+			}
+			continue
+		}
+		if res == nil || res.StatusCode != tt.status {
+			t.Errorf("%s %s: Expected %d, got %v", tt.method, tt.header, tt.status, res)
+		}
+	}
+}
+
+func TestContext_ConditionalETags_WeakAndStrong(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		header string
+		value  string
+		etag   string
+		status int
+	}{
+		{"if-none-match weak remote", "GET", "If-None-Match", `W/"abc"`, "abc", http.StatusNotModified},
+		{"if-none-match weak local", "GET", "If-None-Match", `"abc"`, "W/abc", http.StatusNotModified},
+		{"if-none-match both weak", "PUT", "If-None-Match", `W/"abc"`, "W/abc", http.StatusPreconditionFailed},
+		{"if-none-match weak mismatch", "GET", "If-None-Match", `W/"xyz"`, "W/abc", 0},
+		{"if-match strong", "PUT", "If-Match", `"abc"`, "abc", 0},
+		{"if-match weak remote", "PUT", "If-Match", `W/"abc"`, "abc", http.StatusPreconditionFailed},
+		{"if-match weak local", "PUT", "If-Match", `"abc"`, "W/abc", http.StatusPreconditionFailed},
+		{"if-match mixed list", "PUT", "If-Match", `W/"abc", "abc"`, "abc", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			req.Header.Set(tt.header, tt.value)
+			c, _ := newTestContext(req)
+
+			res := c.CheckPreconditions(tt.etag, time.Time{})
+			if tt.status == 0 {
+				if res != nil {
+					t.Errorf("Expected no response, got %d", res.StatusCode)
+				}
+				return
+			}
+			if res == nil || res.StatusCode != tt.status {
+				t.Fatalf("Expected %d, got %v", tt.status, res)
+			}
+		})
+	}
+}
+
+func TestContext_ConditionalIfNoneMatch_WeakETagHeader(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("If-None-Match", `"abc"`)
+	c, _ := newTestContext(req)
+
+	res := c.ConditionalIfNoneMatch("W/abc")
+	if res == nil || res.StatusCode != http.StatusNotModified {
+		t.Fatalf("Expected 304, got %v", res)
+	}
+	if etag := res.headers.Get("ETag"); etag != `W/"abc"` {
+		t.Errorf("Expected ETag W/\"abc\", got %s", etag)
+	}
+}
+
+func TestParseETags(t *testing.T) {
+	got := parseETags(` "a", W/"b,c" ,,"d"`)
+	want := []string{`"a"`, `W/"b,c"`, `"d"`}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestContext_Pattern(t *testing.T) {
+	var patterns []string
+	record := func(c *Context) *Response {
+		patterns = append(patterns, c.Pattern())
+		return Respond().NoContent()
+	}
+	s := NewServer().NotFound(record)
+	s.GET("/users/{id}", record)
+	s.Group("/api").Group("/v1").POST("/items/{id...}", record)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/users/42", nil),
+		httptest.NewRequest("POST", "/api/v1/items/a/b", nil),
+		httptest.NewRequest("GET", "/missing", nil),
+	} {
+		s.Handler().ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := []string{"/users/{id}", "/api/v1/items/{id...}", "/missing"}
+	if !slices.Equal(patterns, expected) {
+		t.Errorf("Expected patterns %v, got %v", expected, patterns)
+	}
+}
+
+func TestContext_UUIDPathValue(t *testing.T) {
+	s := NewServer()
+	s.GET("/users/{id}", func(c *Context) *Response {
+		id, res := c.UUIDPathValue("id")
+		if res != nil {
+			return res
+		}
+		return Respond().Text(id)
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users/123e4567-e89b-12d3-a456-426614174000", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "123e4567-e89b-12d3-a456-426614174000" {
+		t.Errorf("Expected 200 with the UUID, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users/42", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for malformed UUID, got %d", w.Code)
+	}
+}
+
+type testArrayReader struct {
+	count int
+	sent  int
+	buf   []byte
+}
+
+func (r *testArrayReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		switch {
+		case r.sent == 0:
+			r.buf = []byte(`[{"id":0}`)
+		case r.sent < r.count:
+			r.buf = []byte(`,{"id":` + strconv.Itoa(r.sent) + `}`)
+		case r.sent == r.count:
+			r.buf = []byte(`]`)
+		default:
+			return 0, io.EOF
+		}
+		r.sent++
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestContext_StreamJSONArray(t *testing.T) {
+	const count = 100000
+	c, _ := newTestContext(httptest.NewRequest("POST", "/", &testArrayReader{count: count}))
+
+	n, sum := 0, 0
+	res := c.StreamJSONArray(func(decode func(any) error) error {
+		var item struct {
+			ID int `json:"id"`
+		}
+		if err := decode(&item); err != nil {
+			return err
+		}
+		n++
+		sum += item.ID
+		return nil
+	})
+
+	if res != nil {
+		t.Fatalf("Expected no response, got %d", res.StatusCode)
+	}
+	if n != count || sum != count*(count-1)/2 {
+		t.Errorf("Expected %d elements, got %d", count, n)
+	}
+}
+
+func TestContext_StreamJSONArray_Malformed(t *testing.T) {
+	for _, body := range []string{`{"id":1}`, `[{"id":1},`, `[{"id":"x"}]`} {
+		c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		res := c.StreamJSONArray(func(decode func(any) error) error {
+			var item struct {
+				ID int `json:"id"`
+			}
+			return decode(&item)
+		})
+		if res == nil || res.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %v", body, res)
+		}
+	}
+}
+
+func TestContext_BearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer abc.def", "abc.def", true},
+		{"bearer abc", "abc", true},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"Bearer ", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", tt.header)
+		c, _ := newTestContext(req)
+
+		token, ok := c.BearerToken()
+		if token != tt.token || ok != tt.ok {
+			t.Errorf("Expected (%q, %v) for %q, got (%q, %v)", tt.token, tt.ok, tt.header, token, ok)
+		}
+	}
+}
+
+func TestContext_AuthScheme(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Digest username=\"alice\", realm=\"x\"")
+	c, _ := newTestContext(req)
+
+	scheme, credentials := c.AuthScheme()
+	if scheme != "Digest" || credentials != "username=\"alice\", realm=\"x\"" {
+		t.Errorf("Expected Digest scheme with credentials, got %q %q", scheme, credentials)
+	}
+}
+
+func TestContext_IsPrefetch(t *testing.T) {
+	for header, expected := range map[string]bool{"prefetch": true, "prefetch;prerender": true, "": false} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Sec-Purpose", header)
+		c, _ := newTestContext(req)
+		if got := c.IsPrefetch(); got != expected {
+			t.Errorf("Expected %v for %q, got %v", expected, header, got)
+		}
+	}
+}
+
+func TestContext_GetRawData_MaxRequestBody(t *testing.T) {
+	s := NewServer().SetMaxRequestBody(8)
+	s.POST("/", func(c *Context) *Response {
+		b, err := c.GetRawData()
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if !errors.As(err, &maxBytesErr) {
+				return Respond().Error(err)
+			}
+			return Respond().Status(http.StatusRequestEntityTooLarge)
+		}
+		return Respond().Text(string(b))
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("12345678")))
+	if w.Code != 200 || w.Body.String() != "12345678" {
+		t.Errorf("Expected body within limit to be read, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("123456789")))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+}
+
+func TestContext_Body(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"Bob"}`)))
+
+	b, err := c.Body()
+	if err != nil || string(b) != `{"name":"Bob"}` {
+		t.Fatalf("Expected body, got %s, %v", b, err)
+	}
+	var data struct {
+		Name string `json:"name"`
+	}
+	if res := c.BindJSON(&data); res != nil || data.Name != "Bob" {
+		t.Errorf("Expected body to be bindable after Body, got %+v", data)
+	}
+}
+
+func TestContext_Trailers(t *testing.T) {
+	s := NewServer()
+	s.POST("/upload", func(c *Context) *Response {
+		b, err := c.Body()
+		if err != nil {
+			return Respond().Error(err)
+		}
+		return Respond().Text(string(b) + ":" + c.Trailers().Get("X-Checksum"))
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	req, _ := http.NewRequest("POST", ts.URL+"/upload", io.MultiReader(strings.NewReader("data")))
+	req.Trailer = http.Header{"X-Checksum": {"abc"}}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	if body, _ := io.ReadAll(res.Body); string(body) != "data:abc" {
+		t.Errorf("Expected body and trailer, got %s", body)
+	}
+}
+
+func newMultipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range files {
+		fw, err := mw.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write([]byte(content))
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/upload", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestContext_BindMultipart(t *testing.T) {
+	type upload struct {
+		Title  string   `form:"title" validate:"required"`
+		Size   int      `form:"size"`
+		Public bool     `form:"public"`
+		Tags   []string `form:"tag"`
+	}
+	req := newMultipartRequest(t, map[string]string{"title": "report", "size": "42", "public": "true", "tag": "q1"}, map[string]string{"document": "content"})
+	c, _ := newTestContext(req)
+
+	var data upload
+	files, res := c.BindMultipart(&data)
+	if res != nil {
+		t.Fatalf("Expected no response, got %d", res.StatusCode)
+	}
+	if data.Title != "report" || data.Size != 42 || !data.Public || len(data.Tags) != 1 || data.Tags[0] != "q1" {
+		t.Errorf("Expected bound fields, got %+v", data)
+	}
+	if len(files["document"]) != 1 || files["document"][0].Filename != "document.txt" {
+		t.Fatalf("Expected uploaded file, got %v", files)
+	}
+	f, err := files["document"][0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if b, _ := io.ReadAll(f); string(b) != "content" {
+		t.Errorf("Expected file content, got %s", b)
+	}
+
+	c, _ = newTestContext(newMultipartRequest(t, map[string]string{"size": "x"}, nil))
+	if _, res := c.BindMultipart(&upload{}); res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid field")
+	}
+	c, _ = newTestContext(newMultipartRequest(t, map[string]string{"size": "1"}, nil))
+	if _, res := c.BindMultipart(&upload{}); res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for missing required field")
+	}
+}
+
+func TestContext_Bind(t *testing.T) {
+	type item struct {
+		Name  string `json:"name" xml:"name" form:"name" validate:"required"`
+		Count int    `json:"count" xml:"count" form:"count"`
+	}
+	tests := []struct {
+		contentType string
+		body        string
+		status      int
+	}{
+		{"application/json", `{"name":"pen","count":2}`, 0},
+		{"application/vnd.api+json; charset=utf-8", `{"name":"pen","count":2}`, 0},
+		{"application/xml", `<item><name>pen</name><count>2</count></item>`, 0},
+		{"text/xml; charset=utf-8", `<item><name>pen</name><count>2</count></item>`, 0},
+		{"application/x-www-form-urlencoded", "name=pen&count=2", 0},
+		{"application/xml", `<item><count>2</count></item>`, http.StatusBadRequest},
+		{"application/x-www-form-urlencoded", "count=2", http.StatusBadRequest},
+		{"application/x-www-form-urlencoded", "name=pen&count=x", http.StatusBadRequest},
+		{"text/plain", "pen", http.StatusUnsupportedMediaType},
+		{"", "pen", http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		c, _ := newTestContext(req)
+
+		var data item
+		res := c.Bind(&data)
+		if tt.status != 0 {
+			if res == nil || res.StatusCode != tt.status {
+				t.Errorf("%s %s: Expected %d, got %v", tt.contentType, tt.body, tt.status, res)
+			}
+			continue
+		}
+		if res != nil {
+			t.Errorf("%s: Expected no response, got %d", tt.contentType, res.StatusCode)
+			continue
+		}
+		if data.Name != "pen" || data.Count != 2 {
+			t.Errorf("%s: Expected bound item, got %+v", tt.contentType, data)
+		}
+	}
+}
+
+func TestContext_Bind_MapTarget(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"pen"}`))
+	req.Header.Set("Content-Type", "application/json")
+	c, _ := newTestContext(req)
+	var data map[string]any
+	if res := c.Bind(&data); res != nil || data["name"] != "pen" {
+		t.Errorf("Expected JSON to bind into map, got %v %v", res, data)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("name=pen"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c, _ = newTestContext(req)
+	if res := c.Bind(&data); res == nil || res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for form into map, got %v", res)
+	}
+}
+
+func TestContext_BindForm_Multipart(t *testing.T) {
+	type upload struct {
+		Title string `form:"title"`
+	}
+	c, _ := newTestContext(newMultipartRequest(t, map[string]string{"title": "report"}, nil))
+
+	var data upload
+	if res := c.BindForm(&data); res != nil {
+		t.Fatalf("Expected no response, got %d", res.StatusCode)
+	}
+	if data.Title != "report" {
+		t.Errorf("Expected title report, got %s", data.Title)
+	}
+	if v := c.FormValues().Get("title"); v != "report" {
+		t.Errorf("Expected form value report, got %s", v)
+	}
+}
+
+func TestContext_BindMultipart_UnsupportedFieldType(t *testing.T) {
+	type upload struct {
+		Title     string
+		CreatedAt time.Time
+	}
+	c, _ := newTestContext(newMultipartRequest(t, map[string]string{"Title": "report", "CreatedAt": "x"}, nil))
+	if _, res := c.BindMultipart(&upload{}); res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unsupported field type, got %v", res)
+	}
+
+	c, _ = newTestContext(newMultipartRequest(t, map[string]string{"Title": "report"}, nil))
+	var data upload
+	if _, res := c.BindMultipart(&data); res != nil || data.Title != "report" {
+		t.Errorf("Expected unsupported field without value to be ignored, got %v %+v", res, data)
+	}
+}
+
+func TestContext_BindJSONStrict(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		message string
+	}{
+		{"valid", `{"name":"alice"}`, 0, ""},
+		{"unknown field", `{"name":"alice","nmae":"bob"}`, http.StatusBadRequest, `unknown field "nmae"`},
+		{"empty body", ``, http.StatusBadRequest, "request body is missing"},
+		{"multiple values", `{"name":"alice"}{}`, http.StatusBadRequest, "single JSON value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader(tt.body)))
+			var u user
+			res := c.BindJSONStrict(&u)
+			if tt.status == 0 {
+				if res != nil {
+					t.Fatalf("Expected no response, got %d", res.StatusCode)
+				}
+				if u.Name != "alice" {
+					t.Errorf("Expected name alice, got %s", u.Name)
+				}
+				return
+			}
+			if res == nil || res.StatusCode != tt.status {
+				t.Fatalf("Expected status %d, got %v", tt.status, res)
+			}
+			if msg := res.jsonBody.(ErrorDto).Message; !strings.Contains(msg, tt.message) {
+				t.Errorf("Expected message containing %s, got %s", tt.message, msg)
+			}
+		})
+	}
+}
+
+func TestContext_NegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header    string
+		supported []string
+		expected  string
+	}{
+		{"gzip;q=0.8, br;q=1.0", []string{"gzip", "br"}, "br"},
+		{"gzip, br", []string{"gzip", "br"}, "gzip"},
+		{"", []string{"gzip", "br"}, "identity"},
+		{"deflate", []string{"gzip", "br"}, "identity"},
+		{"gzip;q=0.5, identity", []string{"gzip"}, "identity"},
+		{"*", []string{"br"}, "br"},
+		{"deflate, identity;q=0", []string{"gzip", "br"}, ""},
+		{"deflate, *;q=0", []string{"gzip", "br"}, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", tt.header)
+		c, _ := newTestContext(req)
+		if got := c.NegotiateEncoding(tt.supported...); got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.header, got)
+		}
+	}
+}
+
+func TestContext_FormFile(t *testing.T) {
+	c, _ := newTestContext(newMultipartRequest(t, map[string]string{"title": "report"}, map[string]string{"document": "content"}))
+
+	if c.FormValues().Get("title") != "report" {
+		t.Errorf("Expected form value title")
+	}
+	file, err := c.FormFile("document")
+	if err != nil {
+		t.Fatalf("Expected file, got %v", err)
+	}
+	if len(c.FormFiles("document")) != 1 {
+		t.Errorf("Expected 1 file, got %d", len(c.FormFiles("document")))
+	}
+	if _, err := c.FormFile("missing"); !errors.Is(err, http.ErrMissingFile) {
+		t.Errorf("Expected ErrMissingFile, got %v", err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "uploads", "document.txt")
+	if err := c.SaveUploadedFile(file, dst); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if b, _ := os.ReadFile(dst); string(b) != "content" {
+		t.Errorf("Expected saved file content, got %s", b)
+	}
+
+	c, _ = newTestContext(httptest.NewRequest("POST", "/", strings.NewReader("a=b")))
+	if _, err := c.FormFile("document"); !errors.Is(err, http.ErrNotMultipart) {
+		t.Errorf("Expected ErrNotMultipart, got %v", err)
+	}
+}
+
+func TestContext_MultipartReader(t *testing.T) {
+	c, _ := newTestContext(newMultipartRequest(t, nil, map[string]string{"document": "content"}))
+	mr, err := c.MultipartReader()
+	if err != nil {
+		t.Fatalf("Expected reader, got %v", err)
+	}
+	part, err := mr.NextPart()
+	if err != nil {
+		t.Fatalf("Expected part, got %v", err)
+	}
+	if part.FormName() != "document" {
+		t.Errorf("Expected form name document, got %s", part.FormName())
+	}
+	if b, _ := io.ReadAll(part); string(b) != "content" {
+		t.Errorf("Expected part content, got %s", b)
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("Expected EOF, got %v", err)
+	}
+
+	c, _ = newTestContext(httptest.NewRequest("POST", "/", strings.NewReader("a=b")))
+	if _, err := c.MultipartReader(); !errors.Is(err, http.ErrNotMultipart) {
+		t.Errorf("Expected ErrNotMultipart, got %v", err)
+	}
+}
+
+func TestContext_RemotePort(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		port       int
+		ok         bool
+	}{
+		{"192.0.2.1:54321", 54321, true},
+		{"[2001:db8::1]:443", 443, true},
+		{"192.0.2.1", 0, false},
+		{"192.0.2.1:http", 0, false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		c, _ := newTestContext(req)
+		port, ok := c.RemotePort()
+		if port != tt.port || ok != tt.ok {
+			t.Errorf("Expected %d %v for %s, got %d %v", tt.port, tt.ok, tt.remoteAddr, port, ok)
+		}
+	}
+}
+
+func TestContext_LocalAddr(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8080}
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, addr))
+	c, _ := newTestContext(req)
+	if c.LocalAddr() != "10.0.0.1:8080" {
+		t.Errorf("Expected local address 10.0.0.1:8080, got %s", c.LocalAddr())
+	}
+}
+
+func TestGetAs(t *testing.T) {
+	type user struct{ Name string }
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+	c.Set("user", &user{Name: "alice"})
+
+	u, ok := GetAs[*user](c, "user")
+	if !ok || u.Name != "alice" {
+		t.Errorf("Expected user alice, got %v %v", u, ok)
+	}
+	if _, ok := GetAs[string](c, "user"); ok {
+		t.Errorf("Expected wrong type to fail")
+	}
+	if _, ok := GetAs[*user](c, "missing"); ok {
+		t.Errorf("Expected missing key to fail")
+	}
+	if MustGetAs[*user](c, "user").Name != "alice" {
+		t.Errorf("Expected user alice")
+	}
+
+	expectPanic := func(key, msg string) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil || !strings.Contains(fmt.Sprint(r), msg) {
+				t.Errorf("Expected panic containing %q, got %v", msg, r)
+			}
+		}()
+		MustGetAs[string](c, key)
+	}
+	expectPanic("missing", "didn't find key 'missing'")
+	expectPanic("user", "has type *srv.user, not string")
+}
+
+func TestContext_BindJSONMap(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader(`{"id":9007199254740993,"name":"alice","tags":["a"]}`)))
+	data, res := c.BindJSONMap()
+	if res != nil {
+		t.Fatalf("Expected no response, got %d", res.StatusCode)
+	}
+	id, ok := data["id"].(json.Number)
+	if !ok || id.String() != "9007199254740993" {
+		t.Errorf("Expected precise json.Number, got %v", data["id"])
+	}
+	if data["name"] != "alice" {
+		t.Errorf("Expected name alice, got %v", data["name"])
+	}
+
+	for _, body := range []string{`{"id":`, `[1,2]`, `null`, ``} {
+		c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader(body)))
+		if _, res := c.BindJSONMap(); res == nil || res.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %q", body)
+		}
+	}
+}
+
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestContext_Flush(t *testing.T) {
+	c, w := newTestContext(httptest.NewRequest("GET", "/", nil))
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !w.Flushed {
+		t.Errorf("Expected writer to be flushed")
+	}
+
+	c = NewContext(nonFlushingWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/", nil), NewServer().contextConfig)
+	if err := c.Flush(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+	if _, _, err := c.Hijack(); !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Expected ErrNotSupported, got %v", err)
+	}
+}
+
+func TestContext_ForwardedClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice","roles":["admin"],"exp":1700000000}`))
+	for _, value := range []string{payload, payload + "==", "eyJhbGciOiJub25lIn0." + payload + ".sig"} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Jwt-Claims", value)
+		c, _ := newTestContext(req)
+		claims, err := c.ForwardedClaims("X-Jwt-Claims")
+		if err != nil {
+			t.Fatalf("Expected no error for %s, got %v", value, err)
+		}
+		if claims["sub"] != "alice" || claims["exp"] != float64(1700000000) {
+			t.Errorf("Expected decoded claims, got %v", claims)
+		}
+	}
+
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+	if _, err := c.ForwardedClaims("X-Jwt-Claims"); !errors.Is(err, ErrNoClaims) {
+		t.Errorf("Expected ErrNoClaims, got %v", err)
+	}
+}
+
+func TestContext_RetryAttempt(t *testing.T) {
+	tests := map[string]int{"": 0, "3": 3, " 2 ": 2, "-1": 0, "x": 0}
+	for value, expected := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if value != "" {
+			req.Header.Set("X-Retry-Attempt", value)
+		}
+		c, _ := newTestContext(req)
+		if got := c.RetryAttempt(); got != expected {
+			t.Errorf("Expected %d for %q, got %d", expected, value, got)
+		}
+	}
+}
+
+func TestContext_IntPathValue(t *testing.T) {
+	tests := []struct {
+		value string
+		id    int64
+		ok    bool
+	}{
+		{"42", 42, true},
+		{"abc", 0, false},
+		{"1.5", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users/"+tt.value, nil)
+		req.SetPathValue("id", tt.value)
+		c, _ := newTestContext(req)
+		id, res := c.IntPathValue("id")
+		if int64(id) != tt.id || (res == nil) != tt.ok {
+			t.Errorf("Expected %d %v for %q, got %d %v", tt.id, tt.ok, tt.value, id, res)
+		}
+		if res != nil && res.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", res.StatusCode)
+		}
+		id64, res := c.Int64PathValue("id")
+		if id64 != tt.id || (res == nil) != tt.ok {
+			t.Errorf("Expected %d %v for %q, got %d %v", tt.id, tt.ok, tt.value, id64, res)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/orders/9007199254740993", nil)
+	req.SetPathValue("id", "9007199254740993")
+	c, _ := newTestContext(req)
+	if id, res := c.Int64PathValue("id"); res != nil || id != 9007199254740993 {
+		t.Errorf("Expected int64 id, got %d", id)
+	}
+}
+
+func TestContext_PreferredLanguage(t *testing.T) {
+	tests := []struct {
+		header    string
+		supported []string
+		expected  string
+	}{
+		{"de-CH, de;q=0.9, en;q=0.8", []string{"en", "de"}, "de"},
+		{"en-US, fr;q=0.5", []string{"fr", "en"}, "en"},
+		{"en", []string{"de", "en-GB"}, "en-GB"},
+		{"fr", []string{"de", "en"}, ""},
+		{"", []string{"de", "en"}, "de"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", tt.header)
+		c, _ := newTestContext(req)
+		if got := c.PreferredLanguage(tt.supported...); got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.header, got)
+		}
+	}
+}
+
+func TestContext_Languages(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "fr;q=0.5, de-CH, *;q=0.1, en;q=0.8, it;q=0")
+	c, _ := newTestContext(req)
+	if languages := c.Languages(); strings.Join(languages, ",") != "de-CH,en,fr" {
+		t.Errorf("Expected de-CH,en,fr, got %v", languages)
+	}
+}
+
+func TestContext_CheckPreconditions(t *testing.T) {
+	lastModified := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	before := lastModified.Add(-time.Hour).Format(http.TimeFormat)
+	after := lastModified.Add(time.Hour).Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{"no conditions", "GET", nil, 0},
+		{"if-none-match matches", "GET", map[string]string{"If-None-Match": `"v1"`}, http.StatusNotModified},
+		{"if-none-match matches unsafe method", "PUT", map[string]string{"If-None-Match": `"v1"`}, http.StatusPreconditionFailed},
+		{"if-none-match wins over if-modified-since", "GET", map[string]string{"If-None-Match": `"v0"`, "If-Modified-Since": after}, 0},
+		{"if-modified-since not modified", "GET", map[string]string{"If-Modified-Since": after}, http.StatusNotModified},
+		{"if-modified-since modified", "GET", map[string]string{"If-Modified-Since": before}, 0},
+		{"if-modified-since ignored for unsafe method", "POST", map[string]string{"If-Modified-Since": after}, 0},
+		{"if-match fails", "PUT", map[string]string{"If-Match": `"v0"`}, http.StatusPreconditionFailed},
+		{"if-match wins over if-unmodified-since", "PUT", map[string]string{"If-Match": `"v1"`, "If-Unmodified-Since": before}, 0},
+		{"if-unmodified-since fails", "PUT", map[string]string{"If-Unmodified-Since": before}, http.StatusPreconditionFailed},
+		{"if-unmodified-since passes", "PUT", map[string]string{"If-Unmodified-Since": after}, 0},
+		{"if-match passes then if-none-match fails", "GET", map[string]string{"If-Match": `"v1"`, "If-None-Match": `"v1"`}, http.StatusNotModified},
+		{"invalid date ignored", "GET", map[string]string{"If-Modified-Since": "yesterday"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			c, _ := newTestContext(req)
+			res := c.CheckPreconditions("v1", lastModified)
+			if tt.status == 0 && res != nil {
+				t.Errorf("Expected no response, got %d", res.StatusCode)
+			}
+			if tt.status != 0 && (res == nil || res.StatusCode != tt.status) {
+				t.Errorf("Expected status %d, got %v", tt.status, res)
+			}
+		})
+	}
+}
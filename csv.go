@@ -0,0 +1,55 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"encoding/csv"
+	"io"
+	"net/http"
+)
+
+// CsvChannel streams rows received from the channel as CSV, preceded by headers if not empty.
+// Rows are written as they arrive and flushed to the client whenever no further row is pending.
+// Streaming ends when the channel is closed, when the request context of c is done, e.g. because the client
+// disconnected, or when writing fails. The channel is no longer drained afterwards, so producers should
+// also stop when c is done. The Content-Type header is automatically set to "text/csv;charset=UTF-8".
+func (r *Response) CsvChannel(c *Context, headers []string, rows <-chan []string) *Response {
+	return r.BodyFn("text/csv;charset=UTF-8", func(w io.Writer) error {
+		cw := csv.NewWriter(w)
+		flush := func() error {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			if f, ok := w.(http.Flusher); ok {
+				f.Flush()
+			}
+			return nil
+		}
+		if len(headers) > 0 {
+			if err := cw.Write(headers); err != nil {
+				return err
+			}
+		}
+		for {
+			select {
+			case <-c.Done():
+				return c.Err()
+			case row, ok := <-rows:
+				if !ok {
+					return flush()
+				}
+				if err := cw.Write(row); err != nil {
+					return err
+				}
+				if len(rows) == 0 {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	})
+}
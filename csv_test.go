@@ -0,0 +1,78 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestResponse_CsvChannel(t *testing.T) {
+	rows := make(chan []string)
+	go func() {
+		defer close(rows)
+		for i := 1; i <= 3; i++ {
+			rows <- []string{strconv.Itoa(i), "name, " + strconv.Itoa(i)}
+		}
+	}()
+
+	c, w := newTestContext(httptest.NewRequest("GET", "/", nil))
+	if err := Respond().CsvChannel(c, []string{"id", "name"}, rows).Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("Expected csv content type, got %s", w.Header().Get("Content-Type"))
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid csv, got %v", err)
+	}
+	if len(records) != 4 || !slices.Equal(records[0], []string{"id", "name"}) || !slices.Equal(records[3], []string{"3", "name, 3"}) {
+		t.Errorf("Expected header and 3 rows, got %v", records)
+	}
+}
+
+func TestResponse_CsvChannel_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rows := make(chan []string)
+	go func() {
+		rows <- []string{"1"}
+		cancel()
+	}()
+
+	c, w := newTestContext(httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	if err := Respond().CsvChannel(c, nil, rows).Write(w); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if w.Body.String() != "1\n" {
+		t.Errorf("Expected rows before cancellation, got %q", w.Body.String())
+	}
+}
+
+type failingWriter struct {
+	http.ResponseWriter
+}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestResponse_CsvChannel_WriteError(t *testing.T) {
+	rows := make(chan []string, 1)
+	rows <- []string{"1"}
+
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+	err := Respond().CsvChannel(c, nil, rows).Write(failingWriter{httptest.NewRecorder()})
+	if err == nil || err.Error() != "connection reset" {
+		t.Errorf("Expected write error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"strings"
+)
+
+const (
+	DigestAlgorithmSHA256 = "sha-256"
+	DigestAlgorithmSHA512 = "sha-512"
+)
+
+var (
+	ErrNoDigest          = errors.New("no digest header")
+	ErrUnsupportedDigest = errors.New("no supported digest algorithm")
+)
+
+// newDigestHash returns a hash for the given RFC 9530 digest algorithm or nil if it is not supported.
+func newDigestHash(algo string) hash.Hash {
+	switch algo {
+	case DigestAlgorithmSHA256:
+		return sha256.New()
+	case DigestAlgorithmSHA512:
+		return sha512.New()
+	}
+	return nil
+}
+
+// formatDigest formats the digest of data as an RFC 9530 dictionary member, e.g. sha-256=:base64:
+func formatDigest(algo string, data []byte) string {
+	h := newDigestHash(algo)
+	h.Write(data)
+	return algo + "=:" + base64.StdEncoding.EncodeToString(h.Sum(nil)) + ":"
+}
+
+// verifyDigests checks data against the digests in a Content-Digest (RFC 9530) or Digest (RFC 3230)
+// header value. All digests with a supported algorithm must match. Returns ErrUnsupportedDigest if
+// the header contains no digest with a supported algorithm.
+func verifyDigests(header string, data []byte) (bool, error) {
+	verified := false
+	for _, member := range strings.Split(header, ",") {
+		algo, value, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok {
+			continue
+		}
+		algo = strings.ToLower(strings.TrimSpace(algo))
+		h := newDigestHash(algo)
+		if h == nil {
+			continue
+		}
+		expected, err := base64.StdEncoding.DecodeString(strings.Trim(strings.TrimSpace(value), ":"))
+		if err != nil {
+			return false, err
+		}
+		h.Write(data)
+		if subtle.ConstantTimeCompare(expected, h.Sum(nil)) != 1 {
+			return false, nil
+		}
+		verified = true
+	}
+	if !verified {
+		return false, ErrUnsupportedDigest
+	}
+	return true, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponse_ContentDigest(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Respond().Json(map[string]int{"id": 1}).ContentDigest(DigestAlgorithmSHA256).Write(w); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sum := sha256.Sum256(w.Body.Bytes())
+	expected := "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+	if got := w.Header().Get("Content-Digest"); got != expected {
+		t.Errorf("Expected Content-Digest %s, got %s", expected, got)
+	}
+}
+
+func TestContext_VerifyContentDigest(t *testing.T) {
+	body := `{"name":"report.pdf"}`
+	sum := sha256.Sum256([]byte(body))
+	digest := "sha-256=:" + base64.StdEncoding.EncodeToString(sum[:]) + ":"
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Digest", digest)
+	c, _ := newTestContext(req)
+	ok, err := c.VerifyContentDigest()
+	if err != nil || !ok {
+		t.Errorf("Expected matching digest, got %v, %v", ok, err)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"other.pdf"}`))
+	req.Header.Set("Content-Digest", digest)
+	c, _ = newTestContext(req)
+	ok, err = c.VerifyContentDigest()
+	if err != nil || ok {
+		t.Errorf("Expected mismatching digest, got %v, %v", ok, err)
+	}
+}
+
+func TestContext_VerifyContentDigest_LegacyDigest(t *testing.T) {
+	body := "hello"
+	sum := sha256.Sum256([]byte(body))
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+	c, _ := newTestContext(req)
+	ok, err := c.VerifyContentDigest()
+	if err != nil || !ok {
+		t.Errorf("Expected matching digest, got %v, %v", ok, err)
+	}
+}
+
+func TestContext_VerifyContentDigest_Missing(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader("hello")))
+	if _, err := c.VerifyContentDigest(); err != ErrNoDigest {
+		t.Errorf("Expected ErrNoDigest, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+// ErrorHandler is a handler that returns errors instead of error responses, e.g. errors of the service
+// layer. Use H to register it as a Handler.
+type ErrorHandler func(c *Context) (*Response, error)
+
+// ErrorMapper turns an error returned by an ErrorHandler into a response.
+type ErrorMapper func(c *Context, err error) *Response
+
+// H adapts fn to a Handler. Errors returned by fn are turned into responses by the ErrorMapper set with
+// Server.SetErrorMapper or, if none is set, DefaultErrorMapper. If fn returns an error, the response
+// returned along with it is ignored.
+func H(fn ErrorHandler) Handler {
+	return func(c *Context) *Response {
+		res, err := fn(c)
+		if err == nil {
+			return res
+		}
+		if c.conf.errorMapper != nil {
+			return c.conf.errorMapper(c, err)
+		}
+		return DefaultErrorMapper(c, err)
+	}
+}
+
+// DefaultErrorMapper maps errors to responses using Response.FromError, e.g. a *ValidationError to
+// 400 Bad Request and a *NotFoundError to 404 Not Found. Other errors are logged and result in 500 Internal
+// Server Error with a generic message.
+func DefaultErrorMapper(c *Context, err error) *Response {
+	return Respond().FromError(err)
+}
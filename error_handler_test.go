@@ -0,0 +1,69 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var errOutOfStock = errors.New("out of stock")
+
+func TestH(t *testing.T) {
+	s := NewServer()
+	s.GET("/items/{id}", H(func(c *Context) (*Response, error) {
+		switch c.PathValue("id") {
+		case "invalid":
+			return nil, fmt.Errorf("binding item: %w", RequireNotEmpty("name", "", nil))
+		case "broken":
+			return nil, errors.New("boom")
+		}
+		return Respond().Text("item"), nil
+	}))
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/items/1", http.StatusOK},
+		{"/items/invalid", http.StatusBadRequest},
+		{"/items/broken", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: Expected status %d, got %d", tt.path, tt.status, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "boom") {
+			t.Errorf("%s: Expected unmapped error not to be exposed, got %s", tt.path, w.Body.String())
+		}
+	}
+}
+
+func TestServer_SetErrorMapper(t *testing.T) {
+	s := NewServer().SetErrorMapper(func(c *Context, err error) *Response {
+		if errors.Is(err, errOutOfStock) {
+			return Respond().Conflict(ErrorDto{Code: "OutOfStock", Message: err.Error()})
+		}
+		return DefaultErrorMapper(c, err)
+	})
+	s.POST("/orders", H(func(c *Context) (*Response, error) {
+		return nil, fmt.Errorf("placing order: %w", errOutOfStock)
+	}))
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/orders", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+	if w.Body.String() != `{"code":"OutOfStock","message":"placing order: out of stock"}` {
+		t.Errorf("Expected mapped error body, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+)
+
+// ErrorPageData is passed to error page templates.
+type ErrorPageData struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// StatusText is the text for the status code, e.g. "Not Found".
+	StatusText string
+	// Body is the JSON body of the response, usually an ErrorDto. It may be nil.
+	Body any
+}
+
+// renderErrorPage replaces the body of an error response with the error page registered for its status code
+// if the client prefers HTML over JSON. The response is left unchanged if the template fails to render.
+func renderErrorPage(pages map[int]*template.Template, c *Context, res *Response) {
+	tmpl, ok := pages[res.StatusCode]
+	if !ok || res.bodyFn != nil || !prefersHTML(c.Accept()) {
+		return
+	}
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, ErrorPageData{
+		StatusCode: res.StatusCode,
+		StatusText: http.StatusText(res.StatusCode),
+		Body:       res.jsonBody,
+	})
+	if err != nil {
+		slog.Error("unable to render error page", "status", res.StatusCode, "error", err)
+		return
+	}
+	res.jsonBody = nil
+	res.Html(buf.String())
+}
+
+// prefersHTML reports whether the Accept header ranks text/html higher than application/json.
+func prefersHTML(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	specs := parseAccept(accept)
+	return mediaQuality(specs, "text/html") > mediaQuality(specs, "application/json")
+}
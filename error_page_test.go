@@ -0,0 +1,49 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_SetErrorPage(t *testing.T) {
+	tmpl := template.Must(template.New("404").Parse(`<h1>{{.StatusCode}} {{.StatusText}}</h1><p>{{.Body.Message}}</p>`))
+	s := NewServer().SetErrorPage(404, tmpl)
+	s.GET("/users/{id}", func(c *Context) *Response {
+		return Respond().NotFound(ErrorDto{Code: "NotFound", Message: "user not found"})
+	})
+
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+
+	if w.Code != 404 {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected html content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if w.Body.String() != "<h1>404 Not Found</h1><p>user not found</p>" {
+		t.Errorf("Expected rendered error page, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+
+	var dto ErrorDto
+	if err := json.Unmarshal(w.Body.Bytes(), &dto); err != nil {
+		t.Fatalf("Expected JSON body, got %s", w.Body.String())
+	}
+	if dto.Code != "NotFound" {
+		t.Errorf("Expected ErrorDto with code NotFound, got %+v", dto)
+	}
+}
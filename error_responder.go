@@ -0,0 +1,40 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+// ErrorResponder creates the response for an error detected by the package, e.g. an invalid request body,
+// from the status code, a machine-readable code like "InvalidRequestBody" and a human-readable message.
+type ErrorResponder func(status int, code, message string) *Response
+
+var errorResponder ErrorResponder = respondErrorDto
+
+// SetErrorResponder sets the function creating the error responses of the package, e.g. to emit a house
+// error envelope instead of an ErrorDto. Passing nil restores the default, which responds with an ErrorDto.
+// SetErrorResponder is not safe for concurrent use and must be called before serving requests.
+func SetErrorResponder(fn ErrorResponder) {
+	if fn == nil {
+		fn = respondErrorDto
+	}
+	errorResponder = fn
+}
+
+func respondErrorDto(status int, code, message string) *Response {
+	return Respond().Status(status).Json(ErrorDto{
+		Code:    code,
+		Message: message,
+	})
+}
+
+// errorBody sets the status and body of r to those of the configured error response.
+// Headers of the error response are added to r, other headers of r are kept.
+func (r *Response) errorBody(status int, code, message string) *Response {
+	e := errorResponder(status, code, message)
+	r.StatusCode = e.StatusCode
+	r.jsonBody, r.rawBody, r.bodyFn = e.jsonBody, e.rawBody, e.bodyFn
+	for k, v := range e.headers {
+		r.headers[k] = v
+	}
+	return r
+}
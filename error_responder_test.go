@@ -0,0 +1,80 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSetErrorResponder(t *testing.T) {
+	SetErrorResponder(func(status int, code, message string) *Response {
+		return Respond().Status(status).Header("X-Error", code).Json(map[string]any{
+			"error": map[string]string{"code": code, "detail": message},
+		})
+	})
+	defer SetErrorResponder(nil)
+
+	s := NewServer()
+	s.POST("/items", func(c *Context) *Response {
+		var data struct{}
+		if res := c.BindJSON(&data); res != nil {
+			return res
+		}
+		return Respond().Error(errors.New("boom"))
+	})
+
+	tests := []struct {
+		method string
+		body   string
+		status int
+		code   string
+	}{
+		{"POST", "", http.StatusBadRequest, "RequestBodyMissing"},
+		{"POST", "{}", http.StatusInternalServerError, "InternalServerError"},
+		{"GET", "", http.StatusMethodNotAllowed, "MethodNotAllowed"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest(tt.method, "/items", strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Errorf("%s %s: Expected status %d, got %d", tt.method, tt.body, tt.status, w.Code)
+		}
+		if w.Header().Get("X-Error") != tt.code || !strings.HasPrefix(w.Body.String(), `{"error":{"code":"`+tt.code+`"`) {
+			t.Errorf("%s %s: Expected custom error %s, got %s", tt.method, tt.body, tt.code, w.Body.String())
+		}
+	}
+
+	SetErrorResponder(nil)
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+	if w.Body.String() != `{"code":"RequestBodyMissing","message":"request body is missing"}` {
+		t.Errorf("Expected default ErrorDto, got %s", w.Body.String())
+	}
+}
+
+func TestSetErrorResponder_MarshalError(t *testing.T) {
+	SetErrorResponder(func(status int, code, message string) *Response {
+		return Respond().Status(status).Json(map[string]any{
+			"error": map[string]string{"code": code, "detail": message},
+		})
+	})
+	defer SetErrorResponder(nil)
+
+	w := httptest.NewRecorder()
+	if err := Respond().Json(map[string]any{"fn": func() {}}).Write(w); err == nil {
+		t.Errorf("Expected encoding error")
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	expected := `{"error":{"code":"InternalServerError","detail":"unable to encode response body"}}`
+	if w.Body.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, w.Body.String())
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// NotFoundError signals that a resource doesn't exist. Response.FromError maps it to 404 Not Found.
+type NotFoundError struct {
+	Code    string
+	Message string
+}
+
+func (e *NotFoundError) Error() string {
+	return errorMessage(e.Code, e.Message, "not found")
+}
+
+func (e *NotFoundError) status() (int, string, string) {
+	return http.StatusNotFound, errorCode(e.Code, "NotFound"), e.Message
+}
+
+// ConflictError signals that a request conflicts with the current state of a resource, e.g. a duplicate.
+// Response.FromError maps it to 409 Conflict.
+type ConflictError struct {
+	Code    string
+	Message string
+}
+
+func (e *ConflictError) Error() string {
+	return errorMessage(e.Code, e.Message, "conflict")
+}
+
+func (e *ConflictError) status() (int, string, string) {
+	return http.StatusConflict, errorCode(e.Code, "Conflict"), e.Message
+}
+
+// UnauthorizedError signals that a request lacks valid authentication. Response.FromError maps it to
+// 401 Unauthorized.
+type UnauthorizedError struct {
+	Code    string
+	Message string
+}
+
+func (e *UnauthorizedError) Error() string {
+	return errorMessage(e.Code, e.Message, "unauthorized")
+}
+
+func (e *UnauthorizedError) status() (int, string, string) {
+	return http.StatusUnauthorized, errorCode(e.Code, "Unauthorized"), e.Message
+}
+
+// ForbiddenError signals that the client is not allowed to perform a request. Response.FromError maps it
+// to 403 Forbidden.
+type ForbiddenError struct {
+	Code    string
+	Message string
+}
+
+func (e *ForbiddenError) Error() string {
+	return errorMessage(e.Code, e.Message, "forbidden")
+}
+
+func (e *ForbiddenError) status() (int, string, string) {
+	return http.StatusForbidden, errorCode(e.Code, "Forbidden"), e.Message
+}
+
+// statusError is implemented by the error types that map to a status code.
+type statusError interface {
+	error
+	status() (status int, code, message string)
+}
+
+// FromError sets the status code and body of the response according to the type of err, which is unwrapped
+// with errors.As: *NotFoundError results in 404, *ConflictError in 409, *UnauthorizedError in 401 and
+// *ForbiddenError in 403 with an ErrorDto body. A *ValidationError results in 400 with the validation error
+// as body and exceeding the request body limit in 413. Other errors are logged and result in 500 Internal
+// Server Error with a generic message, so that their details are not exposed to the client.
+func (r *Response) FromError(err error) *Response {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		r.StatusCode = http.StatusBadRequest
+		return r.Json(validationErr)
+	}
+	var se statusError
+	if errors.As(err, &se) {
+		status, code, message := se.status()
+		return r.errorBody(status, code, message)
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return r.errorBody(http.StatusRequestEntityTooLarge, "RequestBodyTooLarge", tooLargeMessage(maxBytesErr.Limit))
+	}
+	slog.Error("unmapped error", "error", err)
+	return r.errorBody(http.StatusInternalServerError, "InternalServerError", "internal server error")
+}
+
+func errorMessage(code, message, fallback string) string {
+	if message != "" {
+		return message
+	}
+	if code != "" {
+		return code
+	}
+	return fallback
+}
+
+func errorCode(code, fallback string) string {
+	if code != "" {
+		return code
+	}
+	return fallback
+}
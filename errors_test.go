@@ -0,0 +1,51 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestResponse_FromError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		body   any
+	}{
+		{&NotFoundError{Message: "user not found"}, http.StatusNotFound, ErrorDto{Code: "NotFound", Message: "user not found"}},
+		{fmt.Errorf("creating user: %w", &ConflictError{Code: "EmailTaken", Message: "email is taken"}), http.StatusConflict, ErrorDto{Code: "EmailTaken", Message: "email is taken"}},
+		{&UnauthorizedError{}, http.StatusUnauthorized, ErrorDto{Code: "Unauthorized"}},
+		{&ForbiddenError{Message: "not the owner"}, http.StatusForbidden, ErrorDto{Code: "Forbidden", Message: "not the owner"}},
+		{&http.MaxBytesError{Limit: 10}, http.StatusRequestEntityTooLarge, ErrorDto{Code: "RequestBodyTooLarge", Message: "request body must not exceed 10 bytes"}},
+		{errors.New("boom"), http.StatusInternalServerError, ErrorDto{Code: "InternalServerError", Message: "internal server error"}},
+	}
+	for _, tt := range tests {
+		res := Respond().FromError(tt.err)
+		if res.StatusCode != tt.status {
+			t.Errorf("%v: Expected status %d, got %d", tt.err, tt.status, res.StatusCode)
+		}
+		if res.jsonBody != tt.body {
+			t.Errorf("%v: Expected body %+v, got %+v", tt.err, tt.body, res.jsonBody)
+		}
+	}
+
+	validationErr := RequireNotEmpty("name", "", nil)
+	res := Respond().FromError(fmt.Errorf("invalid: %w", validationErr))
+	if res.StatusCode != http.StatusBadRequest || res.jsonBody != validationErr {
+		t.Errorf("Expected 400 with validation error, got %d %+v", res.StatusCode, res.jsonBody)
+	}
+}
+
+func TestNotFoundError_Error(t *testing.T) {
+	if msg := (&NotFoundError{Message: "user not found"}).Error(); msg != "user not found" {
+		t.Errorf("Expected message, got %s", msg)
+	}
+	if msg := (&NotFoundError{}).Error(); msg != "not found" {
+		t.Errorf("Expected default message, got %s", msg)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import "strings"
+
+// parseETags splits the value of an If-Match or If-None-Match header into its entity tags,
+// e.g. `"a", W/"b"` into `"a"` and `W/"b"`. Commas inside quoted tags are kept.
+func parseETags(header string) []string {
+	etags := make([]string, 0)
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(header); i++ {
+		switch header[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case ',':
+			if !inQuotes {
+				if tag := strings.TrimSpace(header[start:i]); tag != "" {
+					etags = append(etags, tag)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if tag := strings.TrimSpace(header[start:]); tag != "" {
+		etags = append(etags, tag)
+	}
+	return etags
+}
+
+// matchETag reports whether any entity tag in header matches the unquoted localEtag, which denotes a
+// weak validator if prefixed with "W/". "*" matches any existing resource, i.e. a non-empty localEtag.
+// The strong comparison requires both tags to be strong, the weak comparison ignores the "W/" prefix.
+func matchETag(header, localEtag string, weakComparison bool) bool {
+	local, localWeak := strings.CutPrefix(localEtag, "W/")
+	if local == "" {
+		return false
+	}
+	quoted := `"` + local + `"`
+	for _, tag := range parseETags(header) {
+		if tag == "*" {
+			return true
+		}
+		remote, remoteWeak := strings.CutPrefix(tag, "W/")
+		if remote != quoted {
+			continue
+		}
+		if weakComparison || (!localWeak && !remoteWeak) {
+			return true
+		}
+	}
+	return false
+}
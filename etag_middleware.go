@@ -0,0 +1,48 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// notModifiedHeaders are the headers of the original response that are kept in a 304 Not Modified response.
+var notModifiedHeaders = []string{"Cache-Control", "Content-Location", "Date", "Expires", "Vary"}
+
+// ETagMiddleware computes a strong ETag from the body of successful GET and HEAD responses and answers
+// requests with a matching If-None-Match header with 304 Not Modified. Responses that already have an ETag
+// and streamed responses, whose body isn't known up front, are left unchanged.
+func ETagMiddleware() Middleware {
+	return func(c *Context, next Handler) *Response {
+		r := next(c)
+		if c.r.Method != http.MethodGet && c.r.Method != http.MethodHead {
+			return r
+		}
+		if r.StatusCode != http.StatusOK || r.bodyFn != nil || r.handler != nil || r.headers.Get("ETag") != "" {
+			return r
+		}
+		body := r.rawBody
+		if r.jsonBody != nil {
+			b, err := r.marshalJSON()
+			if err != nil {
+				return r
+			}
+			body = b
+		}
+		sum := sha256.Sum256(body)
+		etag := hex.EncodeToString(sum[:16])
+		if res := c.ConditionalIfNoneMatch(etag); res != nil {
+			for _, h := range notModifiedHeaders {
+				if v := r.headers.Values(h); len(v) > 0 {
+					res.headers[h] = v
+				}
+			}
+			return res
+		}
+		return r.ETag(etag)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETagMiddleware(t *testing.T) {
+	s := NewServer().Use(ETagMiddleware())
+	s.GET("/users", func(c *Context) *Response {
+		return Respond().CacheControl("no-cache").Json([]string{"alice", "bob"})
+	})
+	s.GET("/stream", func(c *Context) *Response {
+		return Respond().BodyFn("text/plain", func(w io.Writer) error {
+			_, err := io.WriteString(w, "stream")
+			return err
+		})
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || len(etag) != 34 {
+		t.Fatalf("Expected 200 with strong ETag, got %d %s", w.Code, etag)
+	}
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected 304 without body, got %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("ETag") != etag || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("Expected ETag and Cache-Control on 304, got %v", w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+	if w.Header().Get("ETag") != "" {
+		t.Errorf("Expected no ETag for streamed response, got %s", w.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bindValues sets the fields of the struct data points to from values. Fields are matched by the name in
+// their "form" tag or by their name. Fields tagged with "-" and fields without a value are left unchanged.
+// Supported field types are strings, bools, numbers, pointers to them and slices of them. Binding a value
+// to a field of another type fails, as does binding into data that is not a non-nil pointer to a struct.
+func bindValues(values map[string][]string, data any) error {
+	if !isFormTarget(data) {
+		return errFormTarget
+	}
+	return bindStruct(values, reflect.ValueOf(data).Elem())
+}
+
+var errFormTarget = errors.New("form data must be a non-nil pointer to a struct")
+
+// isFormTarget reports whether data is a non-nil pointer to a struct that form values can be bound into.
+func isFormTarget(data any) bool {
+	rv := reflect.ValueOf(data)
+	return rv.Kind() == reflect.Pointer && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct
+}
+
+func bindStruct(values map[string][]string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct {
+			if err := bindStruct(values, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		name := formFieldName(sf)
+		if name == "-" {
+			continue
+		}
+		vals, ok := values[name]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+		if err := bindField(fv, vals); err != nil {
+			return fmt.Errorf("invalid value for '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+func formFieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("form"), ",")
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
+
+func bindField(fv reflect.Value, vals []string) error {
+	switch fv.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, v := range vals {
+			if err := bindScalar(s.Index(i), v); err != nil {
+				return err
+			}
+		}
+		fv.Set(s)
+		return nil
+	case reflect.Pointer:
+		p := reflect.New(fv.Type().Elem())
+		if err := bindScalar(p.Elem(), vals[0]); err != nil {
+			return err
+		}
+		fv.Set(p)
+		return nil
+	}
+	return bindScalar(fv, vals[0])
+}
+
+func bindScalar(fv reflect.Value, v string) error {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(v)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(v, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(v, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(v, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return errors.New("unsupported type " + fv.Type().String())
+	}
+	return nil
+}
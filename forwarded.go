@@ -0,0 +1,70 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ForwardedInfo holds the proxy metadata of a request.
+type ForwardedInfo struct {
+	// For lists the client and proxy addresses, starting with the originating client.
+	For []string
+	// By lists the interfaces of the proxies that received the request.
+	By []string
+	// Proto is the protocol the client used to connect to the first proxy.
+	Proto string
+	// Host is the host the client requested from the first proxy.
+	Host string
+}
+
+// parseForwardedInfo parses the Forwarded header according to RFC 7239, falling back to the
+// X-Forwarded-For, X-Forwarded-Proto and X-Forwarded-Host headers when it is absent.
+func parseForwardedInfo(req *http.Request) ForwardedInfo {
+	info := ForwardedInfo{}
+	if forwarded := req.Header.Get("Forwarded"); forwarded != "" {
+		for _, element := range strings.Split(forwarded, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok {
+					continue
+				}
+				v = strings.Trim(strings.TrimSpace(v), `"`)
+				switch strings.ToLower(strings.TrimSpace(k)) {
+				case "for":
+					info.For = append(info.For, v)
+				case "by":
+					info.By = append(info.By, v)
+				case "proto":
+					if info.Proto == "" {
+						info.Proto = strings.ToLower(v)
+					}
+				case "host":
+					if info.Host == "" {
+						info.Host = v
+					}
+				}
+			}
+		}
+		return info
+	}
+	if xff := req.Header.Get("X-Forwarded-For"); xff != "" {
+		for _, ip := range strings.Split(xff, ",") {
+			if ip = strings.TrimSpace(ip); ip != "" {
+				info.For = append(info.For, ip)
+			}
+		}
+	}
+	info.Proto = strings.ToLower(strings.TrimSpace(firstListValue(req.Header.Get("X-Forwarded-Proto"))))
+	info.Host = strings.TrimSpace(firstListValue(req.Header.Get("X-Forwarded-Host")))
+	return info
+}
+
+// firstListValue returns the first element of a comma separated header value.
+func firstListValue(value string) string {
+	v, _, _ := strings.Cut(value, ",")
+	return v
+}
@@ -7,12 +7,18 @@ package srv
 import (
 	"net"
 	"net/http"
+	"net/netip"
 	"strings"
 )
 
 type IPResolver struct {
 	RemoteIPHeaders      []string
 	TrustRemoteIdHeaders bool
+	// TrustedHops is the number of proxies in front of the server. If it is greater than 0, the client
+	// address is the one TrustedHops hops from the right of the chain of header and remote addresses,
+	// regardless of TrustRemoteIdHeaders. Addresses left of it, which clients can forge, are ignored. If the
+	// entry at that position is not an IP address, the remote address is used.
+	TrustedHops int
 }
 
 func NewIPResolver(remoteIPHeaders []string, trustRemoteIdHeaders bool) *IPResolver {
@@ -22,14 +28,19 @@ func NewIPResolver(remoteIPHeaders []string, trustRemoteIdHeaders bool) *IPResol
 	}
 }
 
+// Resolve returns the chain of IP addresses of req, from the client to the direct remote address. The
+// result always contains at least one element, the remote address, which is empty if RemoteAddr is not
+// an IP address. Proxy headers are only considered if TrustRemoteIdHeaders is set or TrustedHops is
+// greater than 0.
 func (r *IPResolver) Resolve(req *http.Request) []string {
 	remoteIP := getRemoteIP(req)
-	if !r.TrustRemoteIdHeaders || len(r.RemoteIPHeaders) == 0 {
+	if (!r.TrustRemoteIdHeaders && r.TrustedHops <= 0) || len(r.RemoteIPHeaders) == 0 {
 		return []string{remoteIP}
 	}
 	ips := make([]string, 0, 2)
+	entries := make([]string, 0, 2)
 	for _, headerName := range r.RemoteIPHeaders {
-		headerValue := req.Header.Get(headerName)
+		headerValue := strings.Join(req.Header.Values(headerName), ",")
 		if headerValue == "" {
 			continue
 		}
@@ -38,26 +49,53 @@ func (r *IPResolver) Resolve(req *http.Request) []string {
 			rawIPs := strings.Split(headerValue, ",")
 			for _, rawIP := range rawIPs {
 				ip := strings.TrimSpace(rawIP)
+				entries = append(entries, ip)
 				if net.ParseIP(ip) != nil {
 					ips = append(ips, ip)
 				}
 			}
 		}
 	}
+	if r.TrustedHops > 0 {
+		return trustedChain(append(entries, remoteIP), r.TrustedHops)
+	}
 	if len(ips) == 0 || remoteIP != ips[len(ips)-1] {
 		ips = append(ips, remoteIP)
 	}
 	return ips
 }
 
+// trustedChain returns the entries of chain from hops hops from the right. Hops are counted over all
+// entries, including invalid ones, so that a client can't shift the boundary by injecting garbage. If the
+// entry at the boundary is not an IP address, only the remote address, the last entry, is returned.
+// Invalid entries added by trusted proxies are dropped.
+func trustedChain(chain []string, hops int) []string {
+	remoteIP := chain[len(chain)-1]
+	chain = chain[max(len(chain)-1-hops, 0):]
+	if net.ParseIP(chain[0]) == nil {
+		return []string{remoteIP}
+	}
+	ips := make([]string, 0, len(chain))
+	for i, ip := range chain {
+		if i == len(chain)-1 || net.ParseIP(ip) != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// getRemoteIP returns the IP address of req.RemoteAddr without the port. Addresses without a port, e.g.
+// "127.0.0.1", and bracketed IPv6 addresses are accepted. IPv6 zones are kept, e.g. "fe80::1%eth0".
+// It returns an empty string if RemoteAddr is not an IP address, e.g. for unix sockets.
 func getRemoteIP(req *http.Request) string {
-	rawIP, _, err := net.SplitHostPort(strings.TrimSpace(req.RemoteAddr))
+	addr := strings.TrimSpace(req.RemoteAddr)
+	host, _, err := net.SplitHostPort(addr)
 	if err != nil {
-		return ""
+		host = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
 	}
-	ip := net.ParseIP(rawIP)
-	if ip == nil {
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
 		return ""
 	}
-	return ip.String()
+	return ip.Unmap().String()
 }
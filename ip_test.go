@@ -6,6 +6,8 @@ package srv
 
 import (
 	"net/http"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -114,3 +116,66 @@ func TestIPResolver_Resolve_InvalidHeaderIP(t *testing.T) {
 		}
 	}
 }
+
+func TestIPResolver_Resolve_TrustedHops(t *testing.T) {
+	tests := []struct {
+		hops     int
+		xff      string
+		expected []string
+	}{
+		{1, "6.6.6.6, 10.0.0.1", []string{"10.0.0.1", "192.168.1.1"}},
+		{2, "6.6.6.6, 10.0.0.1, 10.0.0.2", []string{"10.0.0.1", "10.0.0.2", "192.168.1.1"}},
+		{3, "10.0.0.1", []string{"10.0.0.1", "192.168.1.1"}},
+		{1, "", []string{"192.168.1.1"}},
+		{1, "192.168.1.1", []string{"192.168.1.1", "192.168.1.1"}},
+		{1, "10.0.0.1, garbage", []string{"192.168.1.1"}},
+		{2, "6.6.6.6, 10.0.0.1, garbage", []string{"10.0.0.1", "192.168.1.1"}},
+		{2, "10.0.0.1, garbage, 10.0.0.2", []string{"192.168.1.1"}},
+		{1, "6.6.6.6\n203.0.113.7", []string{"203.0.113.7", "192.168.1.1"}},
+	}
+	for _, tt := range tests {
+		resolver := NewIPResolver([]string{"X-Forwarded-For"}, false)
+		resolver.TrustedHops = tt.hops
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		if tt.xff != "" {
+			for _, line := range strings.Split(tt.xff, "\n") {
+				req.Header.Add("X-Forwarded-For", line)
+			}
+		}
+
+		ips := resolver.Resolve(req)
+
+		if !slices.Equal(ips, tt.expected) {
+			t.Errorf("%d hops, %q: Expected %v, got %v", tt.hops, tt.xff, tt.expected, ips)
+		}
+	}
+}
+
+func TestGetRemoteIP(t *testing.T) {
+	tests := []struct {
+		remoteAddr string
+		expected   string
+	}{
+		{"192.168.1.1:1234", "192.168.1.1"},
+		{"127.0.0.1", "127.0.0.1"},
+		{"[::1]:443", "::1"},
+		{"::1", "::1"},
+		{"[2001:db8::1]", "2001:db8::1"},
+		{"[fe80::1%eth0]:443", "fe80::1%eth0"},
+		{"fe80::1%eth0", "fe80::1%eth0"},
+		{"[::ffff:10.0.0.1]:80", "10.0.0.1"},
+		{" 10.0.0.1:80 ", "10.0.0.1"},
+		{"@", ""},
+		{"/var/run/app.sock", ""},
+		{"", ""},
+		{"example.com:80", ""},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if ip := getRemoteIP(req); ip != tt.expected {
+			t.Errorf("%q: Expected IP %q, got %q", tt.remoteAddr, tt.expected, ip)
+		}
+	}
+}
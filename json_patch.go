@@ -0,0 +1,287 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrJSONPatchTestFailed = errors.New("json patch test operation failed")
+)
+
+// JSONPatch is a JSON Patch document (RFC 6902).
+type JSONPatch []JSONPatchOperation
+
+// JSONPatchOperation is a single operation of a JSON Patch document.
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Validate checks that the operation is well-formed.
+func (o JSONPatchOperation) Validate() error {
+	if _, err := jsonPointerTokens(o.Path); err != nil {
+		return err
+	}
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return fmt.Errorf("missing value for %s operation on '%s'", o.Op, o.Path)
+		}
+	case "move", "copy":
+		if _, err := jsonPointerTokens(o.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("invalid operation '%s'", o.Op)
+	}
+	return nil
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to a JSON document and returns the patched document.
+// Returns a BadRequest response if an operation is malformed or can't be applied and a Conflict
+// response if a test operation fails.
+func ApplyJSONPatch(doc []byte, patch JSONPatch) ([]byte, *Response) {
+	result, err := applyJSONPatch(doc, patch)
+	if errors.Is(err, ErrJSONPatchTestFailed) {
+		return nil, respondError(http.StatusConflict, "Conflict", err.Error())
+	}
+	if err != nil {
+		return nil, respondError(http.StatusBadRequest, "InvalidPatch", err.Error())
+	}
+	return result, nil
+}
+
+func applyJSONPatch(doc []byte, patch JSONPatch) ([]byte, error) {
+	var root any
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, err
+	}
+	for _, op := range patch {
+		if err := op.Validate(); err != nil {
+			return nil, err
+		}
+		var err error
+		root, err = applyJSONPatchOperation(root, op)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyJSONPatchOperation(root any, op JSONPatchOperation) (any, error) {
+	path, _ := jsonPointerTokens(op.Path)
+	var value any
+	if op.Value != nil {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return nil, err
+		}
+	}
+	switch op.Op {
+	case "add":
+		return jsonPatchAdd(root, path, value)
+	case "remove":
+		return jsonPatchRemove(root, path)
+	case "replace":
+		return jsonPatchReplace(root, path, value)
+	case "move":
+		if op.From == op.Path {
+			return root, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("can't move '%s' into its own child '%s'", op.From, op.Path)
+		}
+		from, _ := jsonPointerTokens(op.From)
+		v, err := jsonPatchGet(root, from)
+		if err != nil {
+			return nil, err
+		}
+		if root, err = jsonPatchRemove(root, from); err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(root, path, v)
+	case "copy":
+		from, _ := jsonPointerTokens(op.From)
+		v, err := jsonPatchGet(root, from)
+		if err != nil {
+			return nil, err
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var c any
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, err
+		}
+		return jsonPatchAdd(root, path, c)
+	case "test":
+		v, err := jsonPatchGet(root, path)
+		if err != nil || !reflect.DeepEqual(v, value) {
+			return nil, fmt.Errorf("%w: '%s'", ErrJSONPatchTestFailed, op.Path)
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("invalid operation '%s'", op.Op)
+}
+
+func jsonPatchAdd(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return jsonPatchModify(root, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			c[key] = value
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			i, err := jsonPatchIndex(key, len(c)+1)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("can't add '%s' to a scalar value", key)
+	})
+}
+
+func jsonPatchRemove(root any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("can't remove the root document")
+	}
+	return jsonPatchModify(root, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			if _, ok := c[key]; !ok {
+				return nil, fmt.Errorf("member '%s' does not exist", key)
+			}
+			delete(c, key)
+			return c, nil
+		case []any:
+			i, err := jsonPatchIndex(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			return append(c[:i], c[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("can't remove '%s' from a scalar value", key)
+	})
+}
+
+func jsonPatchReplace(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return jsonPatchModify(root, path, func(container any, key string) (any, error) {
+		switch c := container.(type) {
+		case map[string]any:
+			if _, ok := c[key]; !ok {
+				return nil, fmt.Errorf("member '%s' does not exist", key)
+			}
+			c[key] = value
+			return c, nil
+		case []any:
+			i, err := jsonPatchIndex(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("can't replace '%s' in a scalar value", key)
+	})
+}
+
+// jsonPatchModify calls fn with the container of the value at path and stores the returned container in its parent.
+func jsonPatchModify(node any, path []string, fn func(container any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return fn(node, path[0])
+	}
+	child, err := jsonPatchChild(node, path[0])
+	if err != nil {
+		return nil, err
+	}
+	child, err = jsonPatchModify(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		n[path[0]] = child
+	case []any:
+		i, _ := strconv.Atoi(path[0])
+		n[i] = child
+	}
+	return node, nil
+}
+
+func jsonPatchGet(node any, path []string) (any, error) {
+	for _, key := range path {
+		var err error
+		if node, err = jsonPatchChild(node, key); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+func jsonPatchChild(node any, key string) (any, error) {
+	switch n := node.(type) {
+	case map[string]any:
+		v, ok := n[key]
+		if !ok {
+			return nil, fmt.Errorf("member '%s' does not exist", key)
+		}
+		return v, nil
+	case []any:
+		i, err := jsonPatchIndex(key, len(n))
+		if err != nil {
+			return nil, err
+		}
+		return n[i], nil
+	}
+	return nil, fmt.Errorf("can't access '%s' of a scalar value", key)
+}
+
+// jsonPatchIndex parses an array index that must be less than max.
+func jsonPatchIndex(key string, max int) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || i >= max || (len(key) > 1 && key[0] == '0') {
+		return 0, fmt.Errorf("invalid array index '%s'", key)
+	}
+	return i, nil
+}
+
+// jsonPointerTokens splits a JSON Pointer (RFC 6901) into its unescaped reference tokens.
+func jsonPointerTokens(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func bindTestPatch(t *testing.T, body string) JSONPatch {
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	c, _ := newTestContext(req)
+	patch, res := c.BindJSONPatch()
+	if res != nil {
+		t.Fatalf("Unexpected response %d", res.StatusCode)
+	}
+	return patch
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	patch := bindTestPatch(t, `[
+		{"op":"test","path":"/version","value":1},
+		{"op":"add","path":"/tags/1","value":"b"},
+		{"op":"add","path":"/owner~1name","value":"alice"},
+		{"op":"replace","path":"/version","value":2},
+		{"op":"remove","path":"/draft"},
+		{"op":"copy","from":"/tags/0","path":"/primary"},
+		{"op":"move","from":"/primary","path":"/tags/-"}
+	]`)
+
+	result, res := ApplyJSONPatch([]byte(`{"version":1,"draft":true,"tags":["a","c"]}`), patch)
+	if res != nil {
+		t.Fatalf("Unexpected response %d", res.StatusCode)
+	}
+	expected := `{"owner/name":"alice","tags":["a","b","c","a"],"version":2}`
+	if string(result) != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
+	}
+}
+
+func TestApplyJSONPatch_TestFailed(t *testing.T) {
+	patch := bindTestPatch(t, `[{"op":"test","path":"/version","value":2},{"op":"replace","path":"/version","value":3}]`)
+
+	_, res := ApplyJSONPatch([]byte(`{"version":1}`), patch)
+	if res == nil || res.StatusCode != http.StatusConflict {
+		t.Errorf("Expected 409, got %v", res)
+	}
+}
+
+func TestContext_BindJSONPatch_Malformed(t *testing.T) {
+	for _, body := range []string{`[{"op":"jump","path":"/a"}]`, `[{"op":"add","path":"/a"}]`, `[{"op":"remove","path":"a"}]`} {
+		req := httptest.NewRequest("PATCH", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		c, _ := newTestContext(req)
+		if _, res := c.BindJSONPatch(); res == nil || res.StatusCode != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %v", body, res)
+		}
+	}
+
+	patch := JSONPatch{{Op: "remove", Path: "/missing"}}
+	if _, res := ApplyJSONPatch([]byte(`{}`), patch); res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for removing a missing member, got %v", res)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"slices"
+	"strings"
+)
+
+// LinkBuilder builds the value of a "Link" header according to RFC 8288.
+type LinkBuilder struct {
+	links []string
+}
+
+// NewLinkBuilder creates a new, empty LinkBuilder.
+func NewLinkBuilder() *LinkBuilder {
+	return &LinkBuilder{}
+}
+
+// Add adds a link to uri with the relation type rel and the given target attributes, e.g. "type" or
+// "title". The attributes are sorted by name and their values are quoted. Add panics if rel is empty.
+func (b *LinkBuilder) Add(uri, rel string, params map[string]string) *LinkBuilder {
+	if rel == "" {
+		panic("rel must not be empty")
+	}
+	var sb strings.Builder
+	sb.WriteString("<" + uri + ">; rel=" + quoteLinkParam(rel))
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		sb.WriteString("; " + k + "=" + quoteLinkParam(params[k]))
+	}
+	b.links = append(b.links, sb.String())
+	return b
+}
+
+// AddPagination adds the next, prev, first and last links of a paginated list. Empty URIs are skipped,
+// e.g. prev on the first page.
+func (b *LinkBuilder) AddPagination(next, prev, first, last string) *LinkBuilder {
+	for _, l := range [][2]string{{next, "next"}, {prev, "prev"}, {first, "first"}, {last, "last"}} {
+		if l[0] != "" {
+			b.Add(l[0], l[1], nil)
+		}
+	}
+	return b
+}
+
+// Build returns the comma-separated links. It returns an empty string if no link was added.
+func (b *LinkBuilder) Build() string {
+	return strings.Join(b.links, ", ")
+}
+
+var linkParamReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func quoteLinkParam(v string) string {
+	return `"` + linkParamReplacer.Replace(v) + `"`
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import "testing"
+
+func TestLinkBuilder(t *testing.T) {
+	b := NewLinkBuilder().
+		Add("https://example.com/style.css", "stylesheet", map[string]string{"type": "text/css", "title": `the "main" style`}).
+		AddPagination("/users?page=3", "/users?page=1", "/users?page=1", "")
+
+	expected := `<https://example.com/style.css>; rel="stylesheet"; title="the \"main\" style"; type="text/css", ` +
+		`</users?page=3>; rel="next", </users?page=1>; rel="prev", </users?page=1>; rel="first"`
+	if v := b.Build(); v != expected {
+		t.Errorf("Expected %s, got %s", expected, v)
+	}
+}
+
+func TestResponse_Links(t *testing.T) {
+	res := Respond().
+		Links(NewLinkBuilder().AddPagination("/next", "", "", "")).
+		Links(NewLinkBuilder())
+	if links := res.headers.Values("Link"); len(links) != 1 || links[0] != `</next>; rel="next"` {
+		t.Errorf("Expected a single next link, got %v", links)
+	}
+}
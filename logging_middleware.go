@@ -6,23 +6,53 @@ package srv
 
 import (
 	"log/slog"
+	"slices"
 	"time"
 )
 
+// LoggingConfig configures the logging middleware.
+type LoggingConfig struct {
+	// Logger is the logger to write to. Defaults to slog.Default().
+	Logger *slog.Logger
+	// Level is the level the request is logged with. Defaults to slog.LevelInfo.
+	Level slog.Level
+	// SkipPaths lists request paths that are not logged, e.g. health checks.
+	SkipPaths []string
+	// Fields returns additional attributes that are added to the log record.
+	Fields func(c *Context, r *Response) []slog.Attr
+}
+
 // LoggingMiddleware logs the request and response status.
 func LoggingMiddleware() Middleware {
+	return LoggingMiddlewareWithConfig(LoggingConfig{})
+}
+
+// LoggingMiddlewareWithConfig logs the request and response status using the given configuration.
+func LoggingMiddlewareWithConfig(cfg LoggingConfig) Middleware {
 	return func(c *Context, next Handler) *Response {
+		if slices.Contains(cfg.SkipPaths, c.r.URL.Path) {
+			return next(c)
+		}
 		start := time.Now()
 		r := next(c)
 
 		return r.AfterWrite(func() {
-			slog.Info("request",
-				"ip", c.ClientIP(),
-				"method", c.r.Method,
-				"path", c.r.URL.Path,
-				"status", r.StatusCode,
-				"duration", time.Since(start).Milliseconds(),
-			)
+			logger := cfg.Logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+			attrs := []slog.Attr{
+				slog.String("ip", c.ClientIP()),
+				slog.String("method", c.r.Method),
+				slog.String("path", c.r.URL.Path),
+				slog.Int("status", r.StatusCode),
+				slog.Int("bytes", r.BytesWritten()),
+				slog.Int64("duration", time.Since(start).Milliseconds()),
+			}
+			if cfg.Fields != nil {
+				attrs = append(attrs, cfg.Fields(c, r)...)
+			}
+			logger.LogAttrs(c.r.Context(), cfg.Level, "request", attrs...)
 		})
 	}
 }
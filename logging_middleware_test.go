@@ -0,0 +1,40 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoggingMiddlewareWithConfig(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewServer().Use(LoggingMiddlewareWithConfig(LoggingConfig{
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		Level:     slog.LevelWarn,
+		SkipPaths: []string{"/health"},
+		Fields: func(c *Context, r *Response) []slog.Attr {
+			return []slog.Attr{slog.String("request_id", "abc")}
+		},
+	}))
+	s.GET("/health", func(c *Context) *Response { return Respond().NoContent() })
+	s.GET("/users", func(c *Context) *Response { return Respond().Text("ok") })
+
+	s.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/health", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Expected skipped path not to be logged, got %s", buf.String())
+	}
+
+	s.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+	out := buf.String()
+	for _, expected := range []string{"level=WARN", "path=/users", "status=200", "request_id=abc"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected log output to contain %s, got %s", expected, out)
+		}
+	}
+}
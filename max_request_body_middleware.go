@@ -0,0 +1,15 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+// MaxRequestBodyMiddleware overrides the request body limit set with Server.SetMaxRequestBody,
+// e.g. to allow larger bodies on upload endpoints. A value of 0 or less disables the limit.
+// The middleware must run before the body is read.
+func MaxRequestBodyMiddleware(max int64) Middleware {
+	return func(c *Context, next Handler) *Response {
+		c.maxRequestBody = max
+		return next(c)
+	}
+}
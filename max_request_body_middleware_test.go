@@ -0,0 +1,94 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxRequestBodyMiddleware(t *testing.T) {
+	s := NewServer().SetMaxRequestBody(16)
+	handler := func(c *Context) *Response {
+		var data map[string]string
+		if res := c.BindJSON(&data); res != nil {
+			return res
+		}
+		return Respond().NoContent()
+	}
+	s.POST("/items", handler)
+	s.POST("/uploads", handler, MaxRequestBodyMiddleware(1024))
+
+	body := `{"name":"` + strings.Repeat("x", 32) + `"}`
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/items", strings.NewReader(body)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "RequestBodyTooLarge") {
+		t.Errorf("Expected error code RequestBodyTooLarge, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/uploads", strings.NewReader(body)))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+}
+
+func TestContext_ParseForm_TooLarge(t *testing.T) {
+	s := NewServer().SetMaxRequestBody(16)
+	s.POST("/form", func(c *Context) *Response {
+		if res := c.ParseForm(); res != nil {
+			return res
+		}
+		return Respond().Text(c.FormValues().Get("name"))
+	})
+	s.POST("/upload", func(c *Context) *Response {
+		if _, err := c.FormFile("document"); err != nil {
+			return Respond().FromError(err)
+		}
+		return Respond().NoContent()
+	})
+
+	post := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w
+	}
+	req := httptest.NewRequest("POST", "/form", strings.NewReader("name="+strings.Repeat("x", 32)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if w := post(req); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for form, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/form", strings.NewReader("name=pen"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if w := post(req); w.Code != http.StatusOK || w.Body.String() != "pen" {
+		t.Errorf("Expected form value pen, got %d %s", w.Code, w.Body.String())
+	}
+
+	req = newMultipartRequest(t, nil, map[string]string{"document": strings.Repeat("x", 64)})
+	req.URL.Path = "/upload"
+	if w := post(req); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 for upload, got %d", w.Code)
+	}
+}
+
+func TestContext_BindForm_TooLarge(t *testing.T) {
+	var data struct {
+		Name string `form:"name"`
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader("name="+strings.Repeat("x", 32)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c, _ := newTestContext(req)
+	c.maxRequestBody = 16
+	if res := c.BindForm(&data); res == nil || res.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %v", res)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testProfile struct {
+	Name     string            `json:"name"`
+	Nickname *string           `json:"nickname,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+func TestContext_BindMergePatch(t *testing.T) {
+	nickname := "bobby"
+	current := &testProfile{Name: "Bob", Nickname: &nickname, Tags: map[string]string{"team": "a", "role": "dev"}}
+
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name":"Robert","nickname":null,"tags":{"role":null}}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	c, _ := newTestContext(req)
+
+	result, res := c.BindMergePatch(current)
+	if res != nil {
+		t.Fatalf("Unexpected response %d", res.StatusCode)
+	}
+	patched := result.(*testProfile)
+	if patched.Name != "Robert" {
+		t.Errorf("Expected name Robert, got %s", patched.Name)
+	}
+	if patched.Nickname != nil {
+		t.Errorf("Expected nickname to be removed, got %s", *patched.Nickname)
+	}
+	if len(patched.Tags) != 1 || patched.Tags["team"] != "a" {
+		t.Errorf("Expected tags {team: a}, got %v", patched.Tags)
+	}
+	if current.Name != "Bob" {
+		t.Errorf("Expected current to be unchanged, got %s", current.Name)
+	}
+}
+
+func TestContext_BindMergePatch_ContentType(t *testing.T) {
+	req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name":"Robert"}`))
+	req.Header.Set("Content-Type", "application/json")
+	c, _ := newTestContext(req)
+
+	_, res := c.BindMergePatch(testProfile{})
+	if res == nil || res.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415, got %v", res)
+	}
+}
+
+func TestContext_BindMergePatch_InvalidTarget(t *testing.T) {
+	for _, current := range []any{nil, (*testProfile)(nil), map[string]any{}, "profile"} {
+		req := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"name":"Robert"}`))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		c, _ := newTestContext(req)
+
+		_, res := c.BindMergePatch(current)
+		if res == nil || res.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected 500 for %T, got %v", current, res)
+		}
+	}
+}
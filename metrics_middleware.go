@@ -0,0 +1,215 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetricsBuckets are the upper bounds in seconds of the default latency histogram buckets.
+var DefaultMetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// DefaultCollector is the Collector used by the metrics middleware if no collector is configured.
+var DefaultCollector = NewMemoryCollector()
+
+// MetricsOptions configures the metrics middleware.
+type MetricsOptions struct {
+	// Collector receives the metrics. Defaults to DefaultCollector.
+	Collector Collector
+	// SkipPaths lists request paths that are not recorded, e.g. the metrics endpoint itself.
+	SkipPaths []string
+}
+
+// Collector records request metrics. Implement it to bridge the metrics middleware to a metrics library.
+// The route is the pattern the request matched, e.g. "/users/{id}", or empty if no route matched.
+type Collector interface {
+	// AddInFlight adds delta to the number of requests currently being served.
+	AddInFlight(method, route string, delta int)
+	// ObserveRequest records a completed request with its status code and duration.
+	ObserveRequest(method, route string, status int, duration time.Duration)
+}
+
+// MetricsMiddleware records the number of requests, the number of requests in flight and the request
+// latency, labeled by method, route pattern and status code. The route pattern is used instead of the
+// request path to keep the number of label values bounded. The latency includes writing the response.
+func MetricsMiddleware(opts MetricsOptions) Middleware {
+	if opts.Collector == nil {
+		opts.Collector = DefaultCollector
+	}
+	return func(c *Context, next Handler) *Response {
+		if slices.Contains(opts.SkipPaths, c.r.URL.Path) {
+			return next(c)
+		}
+		method := c.r.Method
+		route := c.route()
+		start := time.Now()
+		opts.Collector.AddInFlight(method, route, 1)
+		returned := false
+		defer func() {
+			// The handler panicked, so no response is written.
+			if !returned {
+				opts.Collector.AddInFlight(method, route, -1)
+			}
+		}()
+		r := next(c)
+		returned = true
+		if c.hijacked {
+			opts.Collector.AddInFlight(method, route, -1)
+			opts.Collector.ObserveRequest(method, route, http.StatusSwitchingProtocols, time.Since(start))
+			return r
+		}
+
+		return r.AfterWrite(func() {
+			opts.Collector.AddInFlight(method, route, -1)
+			opts.Collector.ObserveRequest(method, route, r.StatusCode, time.Since(start))
+		})
+	}
+}
+
+// MemoryCollector is an in-memory Collector. It serves the collected metrics in the Prometheus
+// text exposition format, e.g. when mounted with Server.Mount.
+type MemoryCollector struct {
+	mu       sync.Mutex
+	buckets  []float64
+	inFlight map[metricLabels]int64
+	requests map[metricLabels]*latencyHistogram
+}
+
+type metricLabels struct {
+	method string
+	route  string
+	status int
+}
+
+type latencyHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewMemoryCollector creates a new MemoryCollector with the given latency histogram buckets in seconds.
+// DefaultMetricsBuckets are used if no buckets are given.
+func NewMemoryCollector(buckets ...float64) *MemoryCollector {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &MemoryCollector{
+		buckets:  buckets,
+		inFlight: make(map[metricLabels]int64),
+		requests: make(map[metricLabels]*latencyHistogram),
+	}
+}
+
+// AddInFlight adds delta to the number of requests in flight.
+func (m *MemoryCollector) AddInFlight(method, route string, delta int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[metricLabels{method: method, route: route}] += int64(delta)
+}
+
+// ObserveRequest records a completed request.
+func (m *MemoryCollector) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := metricLabels{method: method, route: route, status: status}
+	h, ok := m.requests[key]
+	if !ok {
+		h = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.requests[key] = h
+	}
+	seconds := duration.Seconds()
+	for i, le := range m.buckets {
+		if seconds <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// ServeHTTP writes the collected metrics in the Prometheus text exposition format.
+func (m *MemoryCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(m.render())
+}
+
+func (m *MemoryCollector) render() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf := &bytes.Buffer{}
+	requestKeys := sortedMetricLabels(m.requests)
+
+	buf.WriteString("# HELP http_requests_total Total number of HTTP requests.\n")
+	buf.WriteString("# TYPE http_requests_total counter\n")
+	for _, k := range requestKeys {
+		fmt.Fprintf(buf, "http_requests_total{%s} %d\n", k.format(true), m.requests[k].count)
+	}
+
+	buf.WriteString("# HELP http_requests_in_flight Number of HTTP requests currently being served.\n")
+	buf.WriteString("# TYPE http_requests_in_flight gauge\n")
+	for _, k := range sortedMetricLabels(m.inFlight) {
+		fmt.Fprintf(buf, "http_requests_in_flight{%s} %d\n", k.format(false), m.inFlight[k])
+	}
+
+	buf.WriteString("# HELP http_request_duration_seconds HTTP request latency in seconds.\n")
+	buf.WriteString("# TYPE http_request_duration_seconds histogram\n")
+	for _, k := range requestKeys {
+		h := m.requests[k]
+		labels := k.format(true)
+		for i, le := range m.buckets {
+			fmt.Fprintf(buf, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(le), h.counts[i])
+		}
+		fmt.Fprintf(buf, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(buf, "http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(h.sum))
+		fmt.Fprintf(buf, "http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+	return buf.Bytes()
+}
+
+// format formats the labels for the exposition format, with or without the status code.
+func (l metricLabels) format(withStatus bool) string {
+	s := `method="` + escapeLabelValue(l.method) + `",route="` + escapeLabelValue(l.route) + `"`
+	if withStatus {
+		s += `,status="` + strconv.Itoa(l.status) + `"`
+	}
+	return s
+}
+
+func sortedMetricLabels[V any](m map[metricLabels]V) []metricLabels {
+	keys := make([]metricLabels, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b metricLabels) int {
+		if c := strings.Compare(a.route, b.route); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.method, b.method); c != 0 {
+			return c
+		}
+		return a.status - b.status
+	})
+	return keys
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	collector := NewMemoryCollector(0.1, 1)
+	s := NewServer().Use(MetricsMiddleware(MetricsOptions{
+		Collector: collector,
+		SkipPaths: []string{"/metrics/"},
+	}))
+	s.GET("/users/{id}", func(c *Context) *Response { return Respond().Text("ok") })
+	s.Group("/api").POST("/items", func(c *Context) *Response { return Respond().BadRequest(nil) })
+	s.Mount("/metrics", collector)
+
+	for _, path := range []string{"/users/1", "/users/2"} {
+		s.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	s.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/items", nil))
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics/", nil))
+	out := w.Body.String()
+	for _, expected := range []string{
+		`http_requests_total{method="GET",route="/users/{id}",status="200"} 2`,
+		`http_requests_total{method="POST",route="/api/items",status="400"} 1`,
+		`http_requests_in_flight{method="GET",route="/users/{id}"} 0`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",status="200",le="+Inf"} 2`,
+		`http_request_duration_seconds_count{method="POST",route="/api/items",status="400"} 1`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected metrics to contain %s, got %s", expected, out)
+		}
+	}
+	if strings.Contains(out, "/users/1") || strings.Contains(out, "/metrics") {
+		t.Errorf("Expected no raw or skipped paths in metrics, got %s", out)
+	}
+}
+
+func TestMemoryCollector_Buckets(t *testing.T) {
+	collector := NewMemoryCollector(1, 0.1)
+	collector.ObserveRequest("GET", "/", 200, 50*time.Millisecond)
+	collector.ObserveRequest("GET", "/", 200, 500*time.Millisecond)
+	collector.ObserveRequest("GET", "/", 200, 5*time.Second)
+
+	out := string(collector.render())
+	for _, expected := range []string{
+		`http_request_duration_seconds_bucket{method="GET",route="/",status="200",le="0.1"} 1`,
+		`http_request_duration_seconds_bucket{method="GET",route="/",status="200",le="1"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="/",status="200",le="+Inf"} 3`,
+		`http_request_duration_seconds_sum{method="GET",route="/",status="200"} 5.55`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected metrics to contain %s, got %s", expected, out)
+		}
+	}
+}
+
+func TestMetricsMiddleware_Panic(t *testing.T) {
+	collector := NewMemoryCollector()
+	mw := MetricsMiddleware(MetricsOptions{Collector: collector})
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+
+	func() {
+		defer func() { _ = recover() }()
+		mw(c, func(c *Context) *Response { panic("boom") })
+	}()
+
+	if len(collector.inFlight) != 1 {
+		t.Fatalf("Expected in-flight gauge to be recorded, got %v", collector.inFlight)
+	}
+	for labels, n := range collector.inFlight {
+		if n != 0 {
+			t.Errorf("Expected no requests in flight for %v, got %d", labels, n)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// acceptSpec represents a single entry of an Accept-* header with its quality value.
+type acceptSpec struct {
+	// value is the lower case value of the entry, raw the value as sent by the client.
+	value string
+	raw   string
+	q     float64
+}
+
+// parseAccept parses an Accept-* header into its entries, ordered by descending quality.
+// Entries with equal quality keep the order in which they appear in the header.
+func parseAccept(header string) []acceptSpec {
+	specs := make([]acceptSpec, 0)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		spec := acceptSpec{q: 1}
+		value, params, _ := strings.Cut(part, ";")
+		spec.raw = strings.TrimSpace(value)
+		spec.value = strings.ToLower(spec.raw)
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			spec.q = q
+		}
+		if spec.value == "" {
+			continue
+		}
+		specs = append(specs, spec)
+	}
+	slices.SortStableFunc(specs, func(a, b acceptSpec) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+	return specs
+}
+
+// negotiate returns the supported value with the highest quality in the given Accept-* header.
+// If the header is empty, the first supported value is returned. If no supported value is
+// acceptable, an empty string is returned.
+func negotiate(header string, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return supported[0]
+	}
+	specs := parseAccept(header)
+	best := ""
+	bestQ := 0.0
+	for _, s := range supported {
+		q := acceptQuality(specs, s)
+		if q > bestQ {
+			best = s
+			bestQ = q
+		}
+	}
+	return best
+}
+
+// negotiateEncoding returns the coding from supported with the highest quality in the Accept-Encoding header.
+// Identity is acceptable unless it is excluded explicitly or by "*;q=0", and it is only chosen
+// if the client prefers it over all supported codings.
+func negotiateEncoding(header string, supported []string) string {
+	if strings.TrimSpace(header) == "" {
+		return ContentEncodingIdentity
+	}
+	specs := parseAccept(header)
+	best := ""
+	bestQ := 0.0
+	for _, s := range supported {
+		q := acceptQuality(specs, s)
+		if q > bestQ {
+			best = s
+			bestQ = q
+		}
+	}
+	identityQ := 1.0
+	if slices.ContainsFunc(specs, func(s acceptSpec) bool { return s.value == ContentEncodingIdentity || s.value == "*" }) {
+		identityQ = acceptQuality(specs, ContentEncodingIdentity)
+	}
+	if identityQ > bestQ {
+		return ContentEncodingIdentity
+	}
+	return best
+}
+
+// acceptQuality returns the quality of value in specs. An exact match takes precedence over the wildcard.
+func acceptQuality(specs []acceptSpec, value string) float64 {
+	value = strings.ToLower(value)
+	wildcard := -1.0
+	for _, spec := range specs {
+		if spec.value == value {
+			return spec.q
+		}
+		if spec.value == "*" && wildcard < 0 {
+			wildcard = spec.q
+		}
+	}
+	if wildcard < 0 {
+		return 0
+	}
+	return wildcard
+}
+
+// mediaQuality returns the quality of mediaType in specs parsed from an Accept header,
+// taking media ranges like "text/*" and "*/*" into account. The most specific range wins.
+func mediaQuality(specs []acceptSpec, mediaType string) float64 {
+	mediaType = strings.ToLower(mediaType)
+	typ, _, _ := strings.Cut(mediaType, "/")
+	q, specificity := 0.0, -1
+	for _, spec := range specs {
+		s := -1
+		switch spec.value {
+		case mediaType:
+			s = 2
+		case typ + "/*":
+			s = 1
+		case "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = spec.q, s
+		}
+	}
+	return q
+}
+
+// negotiateLanguage returns the language tag from supported that best matches the given Accept-Language
+// header. Language ranges match tags exactly or as a prefix, e.g. "en" matches "en-US". If no tag matches
+// a range, a supported tag that is a prefix of the range is used as fallback, e.g. "en-US" matches "en".
+// If the header is empty, the first supported tag is returned. If no tag matches, an empty string is returned.
+func negotiateLanguage(header string, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return supported[0]
+	}
+	excluded := make(map[string]bool)
+	specs := parseAccept(header)
+	for _, spec := range specs {
+		if spec.q == 0 {
+			excluded[spec.value] = true
+		}
+	}
+	for _, spec := range specs {
+		if spec.q == 0 {
+			continue
+		}
+		if spec.value == "*" {
+			for _, tag := range supported {
+				if !excluded[strings.ToLower(tag)] {
+					return tag
+				}
+			}
+			continue
+		}
+		if tag, ok := matchLanguage(spec.value, supported, excluded); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+// matchLanguage matches a single language range against the supported tags, preferring exact matches
+// over tags within the range and tags within the range over fallbacks to a broader tag.
+func matchLanguage(lang string, supported []string, excluded map[string]bool) (string, bool) {
+	within, fallback := "", ""
+	for _, tag := range supported {
+		t := strings.ToLower(tag)
+		if excluded[t] {
+			continue
+		}
+		switch {
+		case t == lang:
+			return tag, true
+		case strings.HasPrefix(t, lang+"-") && within == "":
+			within = tag
+		case strings.HasPrefix(lang, t+"-") && fallback == "":
+			fallback = tag
+		}
+	}
+	if within != "" {
+		return within, true
+	}
+	return fallback, fallback != ""
+}
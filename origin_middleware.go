@@ -0,0 +1,18 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import "net/http"
+
+// OriginMiddleware rejects requests with unsafe methods that don't come from one of allowedOrigins
+// with 403 Forbidden. It is a lightweight CSRF defense, see Context.VerifyOrigin.
+func OriginMiddleware(allowedOrigins ...string) Middleware {
+	return func(c *Context, next Handler) *Response {
+		if !c.VerifyOrigin(allowedOrigins...) {
+			return respondError(http.StatusForbidden, "InvalidOrigin", "request origin is not allowed")
+		}
+		return next(c)
+	}
+}
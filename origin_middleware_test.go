@@ -0,0 +1,58 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginMiddleware(t *testing.T) {
+	s := NewServer().Use(OriginMiddleware("https://example.com"))
+	s.POST("/transfer", func(c *Context) *Response {
+		return Respond().NoContent()
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		status int
+	}{
+		{"matching origin", "Origin", "https://example.com", http.StatusNoContent},
+		{"cross origin", "Origin", "https://evil.example", http.StatusForbidden},
+		{"matching referer", "Referer", "https://example.com/account", http.StatusNoContent},
+		{"cross origin referer", "Referer", "https://evil.example/example.com", http.StatusForbidden},
+		{"missing origin", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/transfer", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestContext_VerifyOrigin(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("GET", "/", nil))
+	if !c.VerifyOrigin("https://example.com") {
+		t.Errorf("Expected safe method to be allowed")
+	}
+
+	req := httptest.NewRequest("POST", "http://example.com/", nil)
+	req.Header.Set("Origin", "http://example.com")
+	c, _ = newTestContext(req)
+	if !c.VerifyOrigin() {
+		t.Errorf("Expected same origin to be allowed")
+	}
+}
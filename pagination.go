@@ -0,0 +1,97 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// PageParam is the query parameter holding the 1-based page number.
+	PageParam = "page"
+	// PageSizeParam is the default query parameter holding the number of items per page. Use
+	// Server.SetPageSizeParam to choose a different one.
+	PageSizeParam = "pageSize"
+)
+
+// Page is a page of a paginated list.
+type Page[T any] struct {
+	Items    []T `json:"items"`
+	Total    int `json:"total"`
+	Page     int `json:"page"`
+	PageSize int `json:"pageSize"`
+}
+
+// Pagination returns the page number, page size and total number of items of the page.
+func (p Page[T]) Pagination() (page, pageSize, total int) {
+	return p.Page, p.PageSize, p.Total
+}
+
+// Paginated is a page of a paginated list, e.g. a Page.
+type Paginated interface {
+	Pagination() (page, pageSize, total int)
+}
+
+// Pagination parses the PageParam and page size query parameters, see Server.SetPageSizeParam. page defaults to 1 and size to
+// defaultSize. Returns a 400 Bad Request response if page is not a positive number or size is not between
+// 1 and maxSize. Pagination panics if defaultSize is not positive or greater than maxSize.
+func (c *Context) Pagination(defaultSize, maxSize int) (page, size int, res *Response) {
+	if defaultSize <= 0 || defaultSize > maxSize {
+		panic("defaultSize must be positive and not greater than maxSize")
+	}
+	page, size = 1, defaultSize
+	if raw := c.Query(PageParam); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil || p < 1 {
+			return 0, 0, respondError(http.StatusBadRequest, "InvalidPagination", "'"+PageParam+"' must be a positive number")
+		}
+		page = p
+	}
+	if raw := c.Query(c.conf.pageSizeParam); raw != "" {
+		s, err := strconv.Atoi(raw)
+		if err != nil || s < 1 || s > maxSize {
+			return 0, 0, respondError(http.StatusBadRequest, "InvalidPagination", "'"+c.conf.pageSizeParam+"' must be between 1 and "+strconv.Itoa(maxSize))
+		}
+		size = s
+	}
+	return page, size, nil
+}
+
+// Page sets the response body to the JSON encoded page p and sets the "X-Total-Count" header to the total
+// number of items. A "Link" header with the first, prev, next and last pages is added. The links are the
+// request URL with the PageParam and page size query parameters set, see Server.SetPageSizeParam.
+func (r *Response) Page(c *Context, p Paginated) *Response {
+	page, size, total := p.Pagination()
+	r.headers.Set("X-Total-Count", strconv.Itoa(total))
+	if size > 0 {
+		r.Links(pageLinkBuilder(c.r.URL, c.conf.pageSizeParam, page, size, total))
+	}
+	return r.Json(p)
+}
+
+// pageLinkBuilder builds the first, prev, next and last links of a paginated list. The links are u with
+// the PageParam and sizeParam query parameters set; other query parameters of u are kept. prev is
+// omitted on the first page and next on the last page.
+func pageLinkBuilder(u *url.URL, sizeParam string, page, size, total int) *LinkBuilder {
+	last := max((total+size-1)/size, 1)
+	link := func(page int) string {
+		q := u.Query()
+		q.Set(PageParam, strconv.Itoa(page))
+		q.Set(sizeParam, strconv.Itoa(size))
+		pageURL := *u
+		pageURL.RawQuery = q.Encode()
+		return pageURL.String()
+	}
+	b := NewLinkBuilder().Add(link(1), "first", nil)
+	if page > 1 {
+		b.Add(link(min(page-1, last)), "prev", nil)
+	}
+	if page < last {
+		b.Add(link(max(page+1, 1)), "next", nil)
+	}
+	return b.Add(link(last), "last", nil)
+}
@@ -0,0 +1,82 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContext_Pagination(t *testing.T) {
+	tests := []struct {
+		query  string
+		page   int
+		size   int
+		status int
+	}{
+		{"", 1, 20, 0},
+		{"?page=3&pageSize=50", 3, 50, 0},
+		{"?page=0", 0, 0, http.StatusBadRequest},
+		{"?page=x", 0, 0, http.StatusBadRequest},
+		{"?pageSize=101", 0, 0, http.StatusBadRequest},
+		{"?pageSize=0", 0, 0, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		c, _ := newTestContext(httptest.NewRequest("GET", "/items"+tt.query, nil))
+		page, size, res := c.Pagination(20, 100)
+		if tt.status != 0 {
+			if res == nil || res.StatusCode != tt.status {
+				t.Errorf("%s: Expected %d, got %v", tt.query, tt.status, res)
+			}
+			continue
+		}
+		if res != nil {
+			t.Errorf("%s: Expected no response, got %d", tt.query, res.StatusCode)
+			continue
+		}
+		if page != tt.page || size != tt.size {
+			t.Errorf("%s: Expected page %d and size %d, got %d and %d", tt.query, tt.page, tt.size, page, size)
+		}
+	}
+}
+
+func TestResponse_Page(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("GET", "/items?q=pen&page=2&pageSize=2", nil))
+	w := httptest.NewRecorder()
+	res := Respond().Page(c, Page[string]{Items: []string{"c", "d"}, Total: 5, Page: 2, PageSize: 2})
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body := w.Body.String(); body != `{"items":["c","d"],"total":5,"page":2,"pageSize":2}` {
+		t.Errorf("Expected page body, got %s", body)
+	}
+	if v := w.Header().Get("X-Total-Count"); v != "5" {
+		t.Errorf("Expected X-Total-Count 5, got %s", v)
+	}
+	expected := `</items?page=1&pageSize=2&q=pen>; rel="first", </items?page=1&pageSize=2&q=pen>; rel="prev", ` +
+		`</items?page=3&pageSize=2&q=pen>; rel="next", </items?page=3&pageSize=2&q=pen>; rel="last"`
+	if link := w.Header().Get("Link"); link != expected {
+		t.Errorf("Expected links %s, got %s", expected, link)
+	}
+}
+
+func TestServer_SetPageSizeParam(t *testing.T) {
+	s := NewServer().SetPageSizeParam("per_page")
+	c := NewContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/items?per_page=5", nil), s.contextConfig)
+	if _, size, res := c.Pagination(10, 50); res != nil || size != 5 {
+		t.Errorf("Expected page size 5, got %d", size)
+	}
+	link := Respond().Page(c, Page[string]{Total: 5, Page: 1, PageSize: 5}).headers.Get("Link")
+	expected := `</items?page=1&per_page=5>; rel="first", </items?page=1&per_page=5>; rel="last"`
+	if link != expected {
+		t.Errorf("Expected links %s, got %s", expected, link)
+	}
+
+	c, _ = newTestContext(httptest.NewRequest("GET", "/items?per_page=500", nil))
+	if _, size, res := c.Pagination(10, 50); res != nil || size != 10 {
+		t.Errorf("Expected other servers to keep the default parameter, got %d", size)
+	}
+}
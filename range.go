@@ -0,0 +1,105 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidRange is returned by Context.ParseRange if the Range header is malformed.
+	// The header should be ignored in that case and the full representation sent.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrRangeNotSatisfiable is returned by Context.ParseRange if no range overlaps the resource.
+	// Respond with 416 Range Not Satisfiable and "Content-Range: bytes */size" in that case.
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+)
+
+// HttpRange is a byte range of a resource.
+type HttpRange struct {
+	Start  int64
+	Length int64
+}
+
+// ContentRange returns the value of the Content-Range header for the range of a resource with
+// the given size, e.g. "bytes 0-499/1234".
+func (r HttpRange) ContentRange(size int64) string {
+	return "bytes " + strconv.FormatInt(r.Start, 10) + "-" + strconv.FormatInt(r.Start+r.Length-1, 10) + "/" + strconv.FormatInt(size, 10)
+}
+
+// ParseRange parses the byte ranges of the Range header for a resource with the given size.
+// Ranges are clamped to the size of the resource and ranges that start after its end are dropped.
+// Returns nil if the header is absent, ErrInvalidRange if it is malformed and ErrRangeNotSatisfiable
+// if none of the ranges overlaps the resource.
+func (c *Context) ParseRange(size int64) ([]HttpRange, error) {
+	return parseRange(c.Range(), size)
+}
+
+func parseRange(header string, size int64) ([]HttpRange, error) {
+	if header == "" {
+		return nil, nil
+	}
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, ErrInvalidRange
+	}
+	ranges := make([]HttpRange, 0)
+	noOverlap := false
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		first, last, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+		var r HttpRange
+		if first == "" {
+			// suffix range, e.g. "-500" for the last 500 bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, ErrInvalidRange
+			}
+			n = min(n, size)
+			if n == 0 {
+				noOverlap = true
+				continue
+			}
+			r.Start = size - n
+			r.Length = n
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, ErrInvalidRange
+			}
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+			r.Start = start
+			if last == "" {
+				r.Length = size - start
+			} else {
+				end, err := strconv.ParseInt(last, 10, 64)
+				if err != nil || end < start {
+					return nil, ErrInvalidRange
+				}
+				r.Length = min(end, size-1) - start + 1
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	if len(ranges) == 0 {
+		if noOverlap {
+			return nil, ErrRangeNotSatisfiable
+		}
+		return nil, ErrInvalidRange
+	}
+	return ranges, nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestContext_ParseRange(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected []HttpRange
+		err      error
+	}{
+		{"", nil, nil},
+		{"bytes=0-499", []HttpRange{{0, 500}}, nil},
+		{"bytes=500-", []HttpRange{{500, 500}}, nil},
+		{"bytes=-200", []HttpRange{{800, 200}}, nil},
+		{"bytes=900-2000", []HttpRange{{900, 100}}, nil},
+		{"bytes=0-0, -1", []HttpRange{{0, 1}, {999, 1}}, nil},
+		{"bytes=-2000", []HttpRange{{0, 1000}}, nil},
+		{"bytes=1000-", nil, ErrRangeNotSatisfiable},
+		{"bytes=2000-3000, 5000-", nil, ErrRangeNotSatisfiable},
+		{"bytes=5-1", nil, ErrInvalidRange},
+		{"items=0-5", nil, ErrInvalidRange},
+		{"bytes=a-b", nil, ErrInvalidRange},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Range", tt.header)
+		}
+		c, _ := newTestContext(req)
+		ranges, err := c.ParseRange(1000)
+		if !errors.Is(err, tt.err) || !slices.Equal(ranges, tt.expected) {
+			t.Errorf("Expected %v %v for %q, got %v %v", tt.expected, tt.err, tt.header, ranges, err)
+		}
+	}
+}
+
+func TestHttpRange_ContentRange(t *testing.T) {
+	if cr := (HttpRange{Start: 0, Length: 500}).ContentRange(1234); cr != "bytes 0-499/1234" {
+		t.Errorf("Expected bytes 0-499/1234, got %s", cr)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig configures the rate limiting middleware.
+type RateLimitConfig struct {
+	// Limit is the number of requests a client may make within Window. Bursts up to Limit are allowed.
+	Limit int
+	// Window is the time in which a client's budget of Limit requests is fully replenished.
+	Window time.Duration
+	// KeyFunc returns the key identifying the client. Defaults to Context.ClientIP.
+	KeyFunc func(c *Context) string
+	// Store holds the token buckets. Defaults to a MemoryRateLimitStore.
+	Store RateLimitStore
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	// Allowed reports whether a token was available.
+	Allowed bool
+	// Remaining is the number of tokens left in the bucket.
+	Remaining int
+	// RetryAfter is the time until the next token is available. It is zero if tokens are left.
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// RateLimitStore stores token buckets by key.
+type RateLimitStore interface {
+	// Take takes a token from the bucket for key, which holds up to limit tokens and refills
+	// completely within window.
+	Take(key string, limit int, window time.Duration) (RateLimitResult, error)
+}
+
+// RateLimitMiddleware limits the number of requests per client using a token bucket per key.
+// The X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers are set on all responses.
+// Requests exceeding the limit receive a 429 response with a Retry-After header.
+// If the store fails, the request is allowed and the error is logged.
+func RateLimitMiddleware(cfg RateLimitConfig) Middleware {
+	if cfg.Limit <= 0 {
+		panic("limit must be greater than 0")
+	}
+	if cfg.Window <= 0 {
+		panic("window must be greater than 0")
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(c *Context) string { return c.ClientIP() }
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore()
+	}
+	limit := strconv.Itoa(cfg.Limit)
+	return func(c *Context, next Handler) *Response {
+		result, err := cfg.Store.Take(cfg.KeyFunc(c), cfg.Limit, cfg.Window)
+		if err != nil {
+			slog.Error("unable to take rate limit token", "error", err)
+			return next(c)
+		}
+		var r *Response
+		if result.Allowed {
+			r = next(c)
+		} else {
+			r = respondError(http.StatusTooManyRequests, "TooManyRequests", "rate limit exceeded").
+				RetryAfterSeconds(ceilSeconds(result.RetryAfter))
+		}
+		return r.
+			Header("X-RateLimit-Limit", limit).
+			Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining)).
+			Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore is an in-memory RateLimitStore. Buckets that have been idle long enough
+// to be full again are evicted.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryRateLimitStore creates a new MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the bucket for key.
+func (s *MemoryRateLimitStore) Take(key string, limit int, window time.Duration) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= window {
+		s.sweep(now, window)
+	}
+	rate := float64(limit) / window.Seconds()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	result := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = secondsToDuration((float64(limit) - b.tokens) / rate)
+	return result, nil
+}
+
+// sweep evicts all buckets that have not been used within window.
+func (s *MemoryRateLimitStore) sweep(now time.Time, window time.Duration) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= window {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
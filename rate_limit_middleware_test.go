@@ -0,0 +1,74 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	s := NewServer().Use(RateLimitMiddleware(RateLimitConfig{Limit: 2, Window: time.Minute}))
+	s.GET("/", func(c *Context) *Response { return Respond().NoContent() })
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	for i, remaining := range []string{"1", "0"} {
+		w := request("10.0.0.1")
+		if w.Code != 204 {
+			t.Fatalf("Expected request %d to be allowed, got %d", i, w.Code)
+		}
+		if w.Header().Get("X-RateLimit-Limit") != "2" || w.Header().Get("X-RateLimit-Remaining") != remaining {
+			t.Errorf("Expected limit 2 and remaining %s, got %s and %s", remaining,
+				w.Header().Get("X-RateLimit-Limit"), w.Header().Get("X-RateLimit-Remaining"))
+		}
+	}
+
+	w := request("10.0.0.1")
+	if w.Code != 429 {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "30" {
+		t.Errorf("Expected Retry-After 30, got %s", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("X-RateLimit-Reset") != "60" {
+		t.Errorf("Expected X-RateLimit-Reset 60, got %s", w.Header().Get("X-RateLimit-Reset"))
+	}
+
+	if w := request("10.0.0.2"); w.Code != 204 {
+		t.Errorf("Expected other client to be allowed, got %d", w.Code)
+	}
+}
+
+func TestMemoryRateLimitStore_Refill(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryRateLimitStore()
+	store.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		store.Take("a", 2, time.Second)
+	}
+	if r, _ := store.Take("a", 2, time.Second); r.Allowed {
+		t.Fatalf("Expected bucket to be empty")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if r, _ := store.Take("a", 2, time.Second); !r.Allowed {
+		t.Errorf("Expected a token to be refilled")
+	}
+
+	now = now.Add(2 * time.Second)
+	store.Take("b", 2, time.Second)
+	if _, ok := store.buckets["a"]; ok {
+		t.Errorf("Expected idle bucket to be evicted")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers nonces of signed requests to detect replays.
+type NonceStore interface {
+	// Use marks nonce as used for ttl. It returns false if the nonce was already used within its ttl.
+	Use(nonce string, ttl time.Duration) (bool, error)
+}
+
+// VerifyTimestamp checks that the Unix timestamp in seconds in the given header is within tolerance
+// of the current time in either direction, which allows for clock skew between client and server.
+// Returns false if the header is missing and an error if it is not a valid timestamp.
+// Combine it with VerifySignature and VerifyNonce to protect signed requests against replays.
+func (c *Context) VerifyTimestamp(header string, tolerance time.Duration) (bool, error) {
+	raw := c.Header(header)
+	if raw == "" {
+		return false, nil
+	}
+	sec, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return false, err
+	}
+	d := time.Since(time.Unix(sec, 0))
+	return d <= tolerance && d >= -tolerance, nil
+}
+
+// VerifyNonce checks that the nonce in the given header has not been used within ttl and marks it as used.
+// The ttl should be at least twice the tolerance passed to VerifyTimestamp, so a nonce can't be replayed
+// while its timestamp is still accepted. Returns false if the header is missing.
+func (c *Context) VerifyNonce(header string, store NonceStore, ttl time.Duration) (bool, error) {
+	nonce := c.Header(header)
+	if nonce == "" {
+		return false, nil
+	}
+	return store.Use(nonce, ttl)
+}
+
+// MemoryNonceStore is an in-memory NonceStore. It is only suitable for a single server instance.
+// Expired nonces are evicted periodically.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryNonceStore creates a new MemoryNonceStore.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Use marks nonce as used for ttl.
+func (s *MemoryNonceStore) Use(nonce string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= ttl {
+		s.sweep(now)
+	}
+	if expiry, ok := s.nonces[nonce]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// sweep evicts all expired nonces.
+func (s *MemoryNonceStore) sweep(now time.Time) {
+	for nonce, expiry := range s.nonces {
+		if !now.Before(expiry) {
+			delete(s.nonces, nonce)
+		}
+	}
+	s.lastSweep = now
+}
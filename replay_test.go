@@ -0,0 +1,70 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestContext_VerifyTimestamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected bool
+		err      bool
+	}{
+		{"in window", strconv.FormatInt(time.Now().Add(-10*time.Second).Unix(), 10), true, false},
+		{"clock skew", strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10), true, false},
+		{"expired", strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10), false, false},
+		{"missing", "", false, false},
+		{"invalid", "yesterday", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			if tt.value != "" {
+				req.Header.Set("X-Timestamp", tt.value)
+			}
+			c, _ := newTestContext(req)
+			ok, err := c.VerifyTimestamp("X-Timestamp", time.Minute)
+			if ok != tt.expected || (err != nil) != tt.err {
+				t.Errorf("Expected %v (error %v), got %v (%v)", tt.expected, tt.err, ok, err)
+			}
+		})
+	}
+}
+
+func TestContext_VerifyNonce(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time { return now }
+
+	verify := func() bool {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("X-Nonce", "abc")
+		c, _ := newTestContext(req)
+		ok, err := c.VerifyNonce("X-Nonce", store, time.Minute)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return ok
+	}
+	if !verify() {
+		t.Errorf("Expected first use of nonce to pass")
+	}
+	if verify() {
+		t.Errorf("Expected replayed nonce to fail")
+	}
+	now = now.Add(2 * time.Minute)
+	if !verify() {
+		t.Errorf("Expected expired nonce to pass again")
+	}
+	if len(store.nonces) != 1 {
+		t.Errorf("Expected expired nonces to be evicted, got %d", len(store.nonces))
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RequestGuardConfig configures the request guard middleware. Zero values disable the respective check.
+type RequestGuardConfig struct {
+	// MaxHeaders is the maximum number of header fields. Every value of a multi-valued header counts.
+	MaxHeaders int
+	// MaxHeaderBytes is the maximum total size of the header names and values.
+	MaxHeaderBytes int
+	// AllowedContentTypes lists the media types accepted for requests with a body, e.g. "application/json".
+	AllowedContentTypes []string
+}
+
+// RequestGuardMiddleware rejects requests with more header fields or header bytes than configured with
+// 431 Request Header Fields Too Large and requests with a body of a content type that is not allowed with
+// 415 Unsupported Media Type. Header limits of the middleware apply per route; use
+// Server.SetMaxHeaderBytes and Server.SetReadHeaderTimeout to limit headers before they are parsed.
+func RequestGuardMiddleware(cfg RequestGuardConfig) Middleware {
+	allowed := make([]string, 0, len(cfg.AllowedContentTypes))
+	for _, ct := range cfg.AllowedContentTypes {
+		allowed = append(allowed, mediaType(ct))
+	}
+	return func(c *Context, next Handler) *Response {
+		if cfg.MaxHeaders > 0 || cfg.MaxHeaderBytes > 0 {
+			count, size := 0, 0
+			for key, values := range c.r.Header {
+				for _, v := range values {
+					count++
+					size += len(key) + len(v)
+				}
+			}
+			if cfg.MaxHeaders > 0 && count > cfg.MaxHeaders {
+				return respondError(http.StatusRequestHeaderFieldsTooLarge, "RequestHeaderFieldsTooLarge", "request must not have more than "+strconv.Itoa(cfg.MaxHeaders)+" header fields")
+			}
+			if cfg.MaxHeaderBytes > 0 && size > cfg.MaxHeaderBytes {
+				return respondError(http.StatusRequestHeaderFieldsTooLarge, "RequestHeaderFieldsTooLarge", "request headers must not exceed "+strconv.Itoa(cfg.MaxHeaderBytes)+" bytes")
+			}
+		}
+		if len(allowed) > 0 && hasBody(c.r) && !slices.Contains(allowed, mediaType(c.ContentType())) {
+			return respondError(http.StatusUnsupportedMediaType, "UnsupportedMediaType", "content type must be one of "+strings.Join(allowed, ", "))
+		}
+		return next(c)
+	}
+}
+
+// hasBody reports whether the request has a body, i.e. a non-zero Content-Length or a chunked body.
+func hasBody(r *http.Request) bool {
+	return r.ContentLength != 0 && r.Body != nil && r.Body != http.NoBody
+}
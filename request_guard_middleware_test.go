@@ -0,0 +1,63 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestGuardMiddleware(t *testing.T) {
+	s := NewServer().Use(RequestGuardMiddleware(RequestGuardConfig{MaxHeaders: 3, MaxHeaderBytes: 64}))
+	s.GET("/items", func(c *Context) *Response { return Respond().NoContent() })
+	s.POST("/items", func(c *Context) *Response { return Respond().NoContent() },
+		RequestGuardMiddleware(RequestGuardConfig{AllowedContentTypes: []string{"application/json"}}))
+
+	tests := []struct {
+		name        string
+		method      string
+		headers     map[string]string
+		body        string
+		contentType string
+		status      int
+	}{
+		{"within limits", "GET", map[string]string{"Accept": "*/*"}, "", "", http.StatusNoContent},
+		{"too many headers", "GET", map[string]string{"A": "1", "B": "2", "C": "3", "D": "4"}, "", "", http.StatusRequestHeaderFieldsTooLarge},
+		{"too many header bytes", "GET", map[string]string{"X-Large": strings.Repeat("x", 64)}, "", "", http.StatusRequestHeaderFieldsTooLarge},
+		{"allowed content type", "POST", nil, "{}", "application/json; charset=utf-8", http.StatusNoContent},
+		{"disallowed content type", "POST", nil, "a=b", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"no body", "POST", nil, "", "", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/items", strings.NewReader(tt.body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			s.Handler().ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestServer_httpServer(t *testing.T) {
+	s := NewServer().SetReadHeaderTimeout(5 * time.Second).SetMaxHeaderBytes(8192)
+	hs := s.httpServer(":8080")
+	if hs.Addr != ":8080" || hs.Handler != s {
+		t.Errorf("Expected address and handler to be set, got %s %v", hs.Addr, hs.Handler)
+	}
+	if hs.ReadHeaderTimeout != 5*time.Second || hs.MaxHeaderBytes != 8192 {
+		t.Errorf("Expected header limits to be set, got %s %d", hs.ReadHeaderTimeout, hs.MaxHeaderBytes)
+	}
+}
@@ -5,12 +5,18 @@
 package srv
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,6 +34,12 @@ const (
 	TransferEncodingCompress = "compress"
 	TransferEncodingDeflate  = "deflate"
 	TransferEncodingGzip     = "gzip"
+
+	ContentEncodingBrotli   = "br"
+	ContentEncodingDeflate  = "deflate"
+	ContentEncodingGzip     = "gzip"
+	ContentEncodingIdentity = "identity"
+	ContentEncodingZstd     = "zstd"
 )
 
 type BodyFn func(w io.Writer) error
@@ -35,13 +47,23 @@ type BodyFn func(w io.Writer) error
 // Response represents an HTTP response that can be customized with status codes, headers, and body content.
 // It provides a fluent interface for building responses with various common HTTP status codes and payloads.
 type Response struct {
-	StatusCode int
-	headers    http.Header
-	cookies    []*http.Cookie
-	bodyFn     BodyFn
-	jsonBody   any
-	rawBody    []byte
-	afterWrite []func()
+	StatusCode   int
+	headers      http.Header
+	cookies      []responseCookie
+	bodyFn       BodyFn
+	jsonBody     any
+	jsonPrefix   string
+	jsonIndent   string
+	jsonNoEscape bool
+	rawBody      []byte
+	afterWrite   []func()
+	bodyHooks    []func(h http.Header, body []byte)
+	handler      http.Handler
+	request      *http.Request
+	omitBody     bool
+	handled      bool
+	trailers     http.Header
+	bytesWritten int
 }
 
 // Respond creates a new Response with default status code 200 OK and empty headers.
@@ -49,8 +71,9 @@ func Respond() *Response {
 	return &Response{
 		StatusCode: http.StatusOK,
 		headers:    http.Header{},
-		cookies:    make([]*http.Cookie, 0),
+		cookies:    make([]responseCookie, 0),
 		afterWrite: make([]func(), 0),
+		bodyHooks:  make([]func(h http.Header, body []byte), 0),
 	}
 }
 
@@ -65,12 +88,22 @@ func (r *Response) Created(body ...any) *Response {
 	return r.statusWithBody(http.StatusCreated, body...)
 }
 
+// Accepted sets the HTTP status code to 202 Accepted and optionally sets the response body.
+func (r *Response) Accepted(body ...any) *Response {
+	return r.statusWithBody(http.StatusAccepted, body...)
+}
+
 // NoContent sets the HTTP status code to 204 No Content.
 func (r *Response) NoContent() *Response {
 	r.StatusCode = http.StatusNoContent
 	return r
 }
 
+// PartialContent sets the HTTP status code to 206 Partial Content and optionally sets the response body.
+func (r *Response) PartialContent(body ...any) *Response {
+	return r.statusWithBody(http.StatusPartialContent, body...)
+}
+
 // MovedPermanently sets the HTTP status code to 301 Moved Permanently and sets the Location header.
 func (r *Response) MovedPermanently(location string) *Response {
 	r.StatusCode = http.StatusMovedPermanently
@@ -85,6 +118,23 @@ func (r *Response) Found(location string) *Response {
 	return r
 }
 
+// Redirect redirects the client to location with the given 3xx status code, e.g. http.StatusSeeOther.
+// For HTMX requests, the status code is 200 OK and the HX-Redirect header is set instead of
+// the Location header, since HTMX doesn't follow redirects transparently.
+// Redirect panics if code is not a redirect status code.
+func (r *Response) Redirect(c *Context, location string, code int) *Response {
+	if code < 300 || code > 399 {
+		panic("invalid redirect status code " + strconv.Itoa(code))
+	}
+	if c.HxRequest() {
+		r.StatusCode = http.StatusOK
+		return r.HxRedirect(location)
+	}
+	r.StatusCode = code
+	r.headers.Set("Location", location)
+	return r
+}
+
 // NotModified sets the HTTP status code to 304 Not Modified.
 func (r *Response) NotModified() *Response {
 	r.StatusCode = http.StatusNotModified
@@ -131,16 +181,66 @@ func (r *Response) Conflict(body ...any) *Response {
 	return r.statusWithBody(http.StatusConflict, body...)
 }
 
+// Gone sets the HTTP status code to 410 Gone and optionally sets the response body.
+func (r *Response) Gone(body ...any) *Response {
+	return r.statusWithBody(http.StatusGone, body...)
+}
+
 // PreconditionFailed sets the HTTP status code to 412 Precondition Failed.
 func (r *Response) PreconditionFailed() *Response {
 	r.StatusCode = http.StatusPreconditionFailed
 	return r
 }
 
+// URITooLong sets the HTTP status code to 414 URI Too Long and optionally sets the response body.
+func (r *Response) URITooLong(body ...any) *Response {
+	return r.statusWithBody(http.StatusRequestURITooLong, body...)
+}
+
+// UnprocessableEntity sets the HTTP status code to 422 Unprocessable Entity and optionally sets the response body,
+// e.g. a *ValidationError.
+func (r *Response) UnprocessableEntity(body ...any) *Response {
+	return r.statusWithBody(http.StatusUnprocessableEntity, body...)
+}
+
+// TooManyRequests sets the HTTP status code to 429 Too Many Requests and optionally sets the response body.
+func (r *Response) TooManyRequests(body ...any) *Response {
+	return r.statusWithBody(http.StatusTooManyRequests, body...)
+}
+
 func (r *Response) InternalServerError(body ...any) *Response {
 	return r.statusWithBody(http.StatusInternalServerError, body...)
 }
 
+// ServiceUnavailable sets the HTTP status code to 503 Service Unavailable and optionally sets the response body.
+func (r *Response) ServiceUnavailable(body ...any) *Response {
+	return r.statusWithBody(http.StatusServiceUnavailable, body...)
+}
+
+// Unavailable sets the HTTP status code to 503 Service Unavailable, e.g. during maintenance or overload.
+// The Retry-After header is set to retryAfter, rounded up to full seconds, and the body is a
+// ProblemDetails with the given detail.
+func (r *Response) Unavailable(retryAfter time.Duration, detail string) *Response {
+	return r.RetryAfterSeconds(ceilSeconds(retryAfter)).Problem(ProblemDetails{
+		Title:  http.StatusText(http.StatusServiceUnavailable),
+		Status: http.StatusServiceUnavailable,
+		Detail: detail,
+	})
+}
+
+// Problem sets the HTTP status code to problem.Status and the response body to problem.
+// The Content-Type header is automatically set to "application/problem+json".
+// If problem.Status is 0, the status code is left unchanged and set in the body.
+func (r *Response) Problem(problem ProblemDetails) *Response {
+	if problem.Status == 0 {
+		problem.Status = r.StatusCode
+	}
+	r.StatusCode = problem.Status
+	r.jsonBody = problem
+	r.ContentType("application/problem+json")
+	return r
+}
+
 func (r *Response) statusWithBody(status int, body ...any) *Response {
 	r.StatusCode = status
 	if len(body) > 0 {
@@ -149,26 +249,44 @@ func (r *Response) statusWithBody(status int, body ...any) *Response {
 	return r
 }
 
-// Error sets the HTTP status code to 500 Internal Server Error and sets the response body to an ErrorDto.
-// If err is nil, the error message will be empty. Otherwise, the error message will be set to err.Error().
+// Error sets the HTTP status code to 500 Internal Server Error and sets the response body to an ErrorDto,
+// or the body created by the ErrorResponder set with SetErrorResponder. If err is nil, the error message
+// will be empty. Otherwise, the error message will be set to err.Error().
 func (r *Response) Error(err error) *Response {
-	r.StatusCode = http.StatusInternalServerError
 	msg := ""
 	if err != nil {
 		msg = err.Error()
 	}
-	return r.Json(ErrorDto{
-		Code:    "InternalServerError",
-		Message: msg,
-	})
+	return r.errorBody(http.StatusInternalServerError, "InternalServerError", msg)
 }
 
-// Header sets a header in the response.
+// Header sets a header in the response, replacing any existing values.
+// Like Header, the helpers for specific headers replace existing values, except for Via, AcceptRanges,
+// VaryBy, Links, WebSub and PageLinks, which add to them.
 func (r *Response) Header(key, value string) *Response {
 	r.headers.Set(key, value)
 	return r
 }
 
+// AddHeader adds a value to a header in the response, keeping existing values, e.g. for multiple
+// "Link" headers.
+func (r *Response) AddHeader(key, value string) *Response {
+	r.headers.Add(key, value)
+	return r
+}
+
+// Headers merges h into the headers of the response. The values of every key in h replace the existing
+// values of that key, other headers are kept.
+func (r *Response) Headers(h http.Header) *Response {
+	for key, values := range h {
+		r.headers.Del(key)
+		for _, value := range values {
+			r.headers.Add(key, value)
+		}
+	}
+	return r
+}
+
 // WwwAuthenticate sets the "WWW-Authenticate" header in the response.
 func (r *Response) WwwHauthenticate(challenge string) *Response {
 	r.headers.Set("WWW-Authenticate", challenge)
@@ -228,18 +346,85 @@ func (r *Response) ETag(etag string) *Response {
 	return r
 }
 
+// WeakETag sets the "ETag" header in the response to a weak validator. The etag value will be
+// automatically wrapped in quotes and prefixed with "W/".
+func (r *Response) WeakETag(etag string) *Response {
+	r.headers.Set("ETag", `W/"`+etag+`"`)
+	return r
+}
+
+// localETag sets the "ETag" header to etag, which denotes a weak validator if prefixed with "W/".
+func (r *Response) localETag(etag string) *Response {
+	if opaque, weak := strings.CutPrefix(etag, "W/"); weak {
+		return r.WeakETag(opaque)
+	}
+	return r.ETag(etag)
+}
+
+// Sign sets the given header to the hex encoded HMAC-SHA256 signature of the response body,
+// prefixed with "sha256=". The signature is computed when the response is written.
+// Streaming bodies set with BodyFn are not signed.
+func (r *Response) Sign(header string, key []byte) *Response {
+	r.bodyHooks = append(r.bodyHooks, func(h http.Header, body []byte) {
+		h.Set(header, signaturePrefix+hex.EncodeToString(computeHMAC(key, body)))
+	})
+	return r
+}
+
+// ContentDigest sets the "Content-Digest" header in the response according to RFC 9530.
+// The digest is computed over the body when the response is written, using either
+// DigestAlgorithmSHA256 or DigestAlgorithmSHA512. Streaming bodies set with BodyFn are not digested.
+func (r *Response) ContentDigest(algo string) *Response {
+	if newDigestHash(algo) == nil {
+		panic("unsupported digest algorithm '" + algo + "'")
+	}
+	r.bodyHooks = append(r.bodyHooks, func(h http.Header, body []byte) {
+		h.Set("Content-Digest", formatDigest(algo, body))
+	})
+	return r
+}
+
 // Vary sets the "Vary" header in the response.
 func (r *Response) Vary(headers ...string) *Response {
 	r.headers.Set("Vary", strings.Join(headers, ", "))
 	return r
 }
 
+// VaryBy adds headers to the "Vary" header in the response, so caches key the response by them.
+// Headers already present are skipped. Unlike Vary, it can be called multiple times, e.g. by
+// middleware and handler, and it leaves caching headers like Cache-Control untouched.
+func (r *Response) VaryBy(headers ...string) *Response {
+	vary := make([]string, 0)
+	for _, v := range r.headers.Values("Vary") {
+		for _, h := range strings.Split(v, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				vary = append(vary, h)
+			}
+		}
+	}
+	for _, h := range headers {
+		if !slices.ContainsFunc(vary, func(v string) bool { return strings.EqualFold(v, h) || v == "*" }) {
+			vary = append(vary, h)
+		}
+	}
+	r.headers.Set("Vary", strings.Join(vary, ", "))
+	return r
+}
+
 // Connection sets the "Connection" header in the response.
 func (r *Response) Connection(value string) *Response {
 	r.headers.Set("Connection", value)
 	return r
 }
 
+// Close sets the "Connection" header to "close" in the response.
+// The http.Server closes the underlying connection after the response has been written
+// when this header is present, so subsequent requests must open a new connection.
+func (r *Response) Close() *Response {
+	r.headers.Set("Connection", "close")
+	return r
+}
+
 // KeepAlive sets the "Keep-Alive" header in the response.
 func (r *Response) KeepAlive(timeout int, max int) *Response {
 	r.headers.Set("Keep-Alive", fmt.Sprintf("timeout=%d, max=%d", timeout, max))
@@ -271,8 +456,16 @@ func (r *Response) AcceptPost(value string) *Response {
 }
 
 // Cookie adds a Set-Cookie header to the ResponseWriter's headers.
+// The cookie is sent without SameSite attribute. Use CookieWithSameSite to set a SameSite mode.
 // The provided cookie must have a valid Name. Invalid cookies may be silently dropped.
 func (r *Response) Cookie(name, value string, maxAge int, path, domain string, secure, httpOnly bool) *Response {
+	return r.CookieWithSameSite(name, value, maxAge, path, domain, secure, httpOnly, http.SameSiteDefaultMode)
+}
+
+// CookieWithSameSite adds a Set-Cookie header with the given SameSite mode to the ResponseWriter's headers.
+// Browsers require cookies with http.SameSiteNoneMode to be secure.
+// The provided cookie must have a valid Name. Invalid cookies may be silently dropped.
+func (r *Response) CookieWithSameSite(name, value string, maxAge int, path, domain string, secure, httpOnly bool, sameSite http.SameSite) *Response {
 	if path == "" {
 		path = "/"
 	}
@@ -284,13 +477,49 @@ func (r *Response) Cookie(name, value string, maxAge int, path, domain string, s
 		Domain:   domain,
 		Secure:   secure,
 		HttpOnly: httpOnly,
+		SameSite: sameSite,
+	})
+}
+
+// DeleteCookie adds a Set-Cookie header that makes the client delete the cookie with the given name.
+// Path and domain must match the ones the cookie was set with, otherwise the cookie is not deleted.
+// An empty path defaults to "/", like in Cookie.
+func (r *Response) DeleteCookie(name, path, domain string) *Response {
+	if path == "" {
+		path = "/"
+	}
+	return r.CookieRaw(&http.Cookie{
+		Name:    name,
+		Path:    path,
+		Domain:  domain,
+		MaxAge:  -1,
+		Expires: time.Unix(0, 0),
 	})
 }
 
 // CookieRaw adds a Set-Cookie header to the ResponseWriter's headers.
 // The provided cookie must have a valid Name. Invalid cookies may be silently dropped.
 func (r *Response) CookieRaw(cookie *http.Cookie) *Response {
-	r.cookies = append(r.cookies, cookie)
+	r.cookies = append(r.cookies, responseCookie{cookie: cookie})
+	return r
+}
+
+// CookiePartitioned adds a Set-Cookie header with the Partitioned attribute (CHIPS) to the ResponseWriter's
+// headers, so that the cookie is stored per top-level site when embedded cross-site.
+// Partitioned cookies must be secure, so Secure is set on a copy of the cookie.
+// The provided cookie must have a valid Name. Invalid cookies may be silently dropped.
+func (r *Response) CookiePartitioned(cookie *http.Cookie) *Response {
+	partitioned := *cookie
+	partitioned.Secure = true
+	r.cookies = append(r.cookies, responseCookie{cookie: &partitioned, partitioned: true})
+	return r
+}
+
+// ConditionalCookie adds a Set-Cookie header like CookieRaw, but only if cond returns true when the
+// response is written. Middleware can use it to set cookies depending on the outcome of the handler,
+// e.g. only for successful responses.
+func (r *Response) ConditionalCookie(cond func() bool, cookie *http.Cookie) *Response {
+	r.cookies = append(r.cookies, responseCookie{cookie: cookie, cond: cond})
 	return r
 }
 
@@ -369,6 +598,15 @@ func (r *Response) ContentLanguage(language string) *Response {
 	return r
 }
 
+// NegotiatedLanguage sets the "Content-Language" header in the response to the language from supported
+// that best matches the request's Accept-Language header. The header is not set if no language matches.
+func (r *Response) NegotiatedLanguage(c *Context, supported ...string) *Response {
+	if lang := c.PreferredLanguage(supported...); lang != "" {
+		r.headers.Set("Content-Language", lang)
+	}
+	return r
+}
+
 // ContentLocation sets the "Content-Location" header in the response.
 func (r *Response) ContentLocation(location string) *Response {
 	r.headers.Set("Content-Location", location)
@@ -389,6 +627,7 @@ func (r *Response) Via(via string) *Response {
 }
 
 // AcceptRanges sets the "Accept-Ranges" header in the response.
+// The value will be added to the existing "Accept-Ranges" header.
 func (r *Response) AcceptRanges() *Response {
 	r.headers.Add("Accept-Ranges", "bytes")
 	return r
@@ -512,18 +751,67 @@ func (r *Response) Trailer(headerNames string) *Response {
 	return r
 }
 
+// SetTrailer sets a trailer field that is sent after the body, e.g. a checksum. Trailers set before the
+// response is written are announced in the "Trailer" header. SetTrailer may also be called from a BodyFn
+// to send a value computed while streaming the body; announce such trailers with Trailer, as net/http
+// drops unannounced trailers of responses that are not chunked. Responses with trailers don't get an
+// automatic Content-Length header. Trailers are not sent for HEAD requests.
+func (r *Response) SetTrailer(key, value string) *Response {
+	if r.trailers == nil {
+		r.trailers = http.Header{}
+	}
+	r.trailers.Set(key, value)
+	return r
+}
+
 // Date sets the "Date" header in the response.
 func (r *Response) Date(t time.Time) *Response {
 	r.headers.Set("Date", t.UTC().Format(http.TimeFormat))
 	return r
 }
 
-// Link sets the "Link" header in the response.
+// Link sets the "Link" header in the response, replacing existing links.
+// Use AddHeader to add multiple links.
 func (r *Response) Link(link string) *Response {
 	r.headers.Set("Link", link)
 	return r
 }
 
+// Links adds a "Link" header with the links of b. Existing Link headers are kept.
+// Nothing is added if b is empty.
+func (r *Response) Links(b *LinkBuilder) *Response {
+	if v := b.Build(); v != "" {
+		r.headers.Add("Link", v)
+	}
+	return r
+}
+
+// WebSub adds "Link" headers advertising the WebSub hub and the canonical topic URL of the response (self),
+// so subscribers can discover where to subscribe. Existing Link headers are kept.
+func (r *Response) WebSub(hub, self string) *Response {
+	r.headers.Add("Link", "<"+hub+`>; rel="hub"`)
+	r.headers.Add("Link", "<"+self+`>; rel="self"`)
+	return r
+}
+
+// PageLinks adds "Link" headers with the first, prev, next and last relations for a paginated list with
+// total items and perPage items per page. The links are baseURL with the "page" and "per_page" query
+// parameters set; other query parameters of baseURL are kept. prev is omitted on the first page and next on
+// the last page. PageLinks panics if baseURL is not a valid URL or perPage is not positive.
+func (r *Response) PageLinks(baseURL string, current, perPage, total int) *Response {
+	if perPage <= 0 {
+		panic("perPage must be positive")
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		panic("invalid base url: " + err.Error())
+	}
+	for _, link := range pageLinkBuilder(u, "per_page", current, perPage, total).links {
+		r.headers.Add("Link", link)
+	}
+	return r
+}
+
 // RetryAfterSeconds sets the "Retry-After" header in the response.
 func (r *Response) RetryAfterSeconds(seconds int) *Response {
 	r.headers.Set("Retry-After", strconv.Itoa(seconds))
@@ -628,6 +916,49 @@ func (r *Response) Json(data any) *Response {
 	return r
 }
 
+// JsonIndent sets the response body to an indented JSON-encoded representation of the provided data.
+// Each element begins on a new line starting with prefix followed by copies of indent.
+// The Content-Type header is automatically set to "application/json;charset=UTF-8".
+func (r *Response) JsonIndent(data any, prefix, indent string) *Response {
+	r.jsonPrefix = prefix
+	r.jsonIndent = indent
+	return r.Json(data)
+}
+
+// JsonStream encodes the provided data directly to the writer instead of buffering it, e.g. for large lists.
+// Encoding errors after the status code has been written are returned from Write and logged.
+// The Content-Type header is automatically set to "application/json;charset=UTF-8".
+func (r *Response) JsonStream(data any) *Response {
+	return r.BodyFn("application/json;charset=UTF-8", func(w io.Writer) error {
+		return r.jsonEncoder(w).Encode(data)
+	})
+}
+
+// SetEscapeHTML specifies whether the characters <, > and & are escaped in JSON strings.
+// They are escaped by default. Disable escaping for APIs that embed raw HTML fragments.
+func (r *Response) SetEscapeHTML(escape bool) *Response {
+	r.jsonNoEscape = !escape
+	return r
+}
+
+func (r *Response) jsonEncoder(w io.Writer) *json.Encoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(!r.jsonNoEscape)
+	enc.SetIndent(r.jsonPrefix, r.jsonIndent)
+	return enc
+}
+
+func (r *Response) marshalJSON() ([]byte, error) {
+	if r.jsonPrefix == "" && r.jsonIndent == "" && !r.jsonNoEscape {
+		return json.Marshal(r.jsonBody)
+	}
+	var buf bytes.Buffer
+	if err := r.jsonEncoder(&buf).Encode(r.jsonBody); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 // Html sets the response body to an HTML string.
 // The Content-Type header is automatically set to "text/html;charset=UTF-8".
 func (r *Response) Html(html string) *Response {
@@ -657,14 +988,92 @@ func (r *Response) BodyFn(contentType string, bodyFn BodyFn) *Response {
 	return r
 }
 
+// Stream sets the response body to the contents of reader, e.g. a file or an upstream response, and sets
+// the Content-Type header. The reader is copied to the client when the response is written and closed
+// afterwards if it is an io.Closer, even if the body is not written, e.g. for HEAD requests.
+func (r *Response) Stream(contentType string, reader io.Reader) *Response {
+	if closer, ok := reader.(io.Closer); ok {
+		r.AfterWrite(func() {
+			_ = closer.Close()
+		})
+	}
+	return r.BodyFn(contentType, func(w io.Writer) error {
+		buf := streamBufferPool.Get().(*[]byte)
+		defer streamBufferPool.Put(buf)
+		_, err := io.CopyBuffer(w, reader, *buf)
+		return err
+	})
+}
+
+var streamBufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 32*1024)
+		return &b
+	},
+}
+
+// BodyFnChecksummed streams the body like BodyFn and sends its SHA-256 digest in the trailer trailerName,
+// e.g. "Content-Digest", so the client can verify the integrity of the download. The digest is formatted
+// like in ContentDigest, e.g. sha-256=:base64:. The trailer is announced in the Trailer header.
+func (r *Response) BodyFnChecksummed(contentType, trailerName string, bodyFn BodyFn) *Response {
+	r.headers.Add("Trailer", trailerName)
+	return r.BodyFn(contentType, func(w io.Writer) error {
+		h := newDigestHash(DigestAlgorithmSHA256)
+		if err := bodyFn(io.MultiWriter(w, h)); err != nil {
+			return err
+		}
+		r.SetTrailer(trailerName, DigestAlgorithmSHA256+"=:"+base64.StdEncoding.EncodeToString(h.Sum(nil))+":")
+		return nil
+	})
+}
+
+// Hijacked marks the response as already handled, e.g. because the handler hijacked the connection or
+// wrote the response itself. Write does not write anything for such a response, but AfterWrite
+// functions are still called.
+func (r *Response) Hijacked() *Response {
+	r.handled = true
+	return r
+}
+
 // Write writes the response to the http.ResponseWriter.
 // It sets the headers and writes the body to the writer.
+// If the JSON body can't be encoded, a 500 Internal Server Error created by the ErrorResponder is
+// written instead and the encoding error is returned. The body is omitted for HEAD requests served by a Server,
+// while all headers, including Content-Length, are sent.
 func (r *Response) Write(w http.ResponseWriter) error {
 	defer func() {
 		for _, fn := range r.afterWrite {
 			fn()
 		}
 	}()
+	if r.handled {
+		return nil
+	}
+
+	body := r.rawBody
+	var marshalErr error
+	if r.jsonBody != nil {
+		b, err := r.marshalJSON()
+		if err != nil {
+			marshalErr = err
+			r.headers.Del("Content-Length")
+			r.headers.Del("Content-Type")
+			r.errorBody(http.StatusInternalServerError, "InternalServerError", "unable to encode response body")
+			b = r.rawBody
+			if r.jsonBody != nil {
+				if b, err = r.marshalJSON(); err != nil {
+					r.ContentType("application/json;charset=UTF-8")
+					b, _ = json.Marshal(ErrorDto{Code: "InternalServerError", Message: "unable to encode response body"})
+				}
+			}
+		}
+		body = b
+	}
+	if r.bodyFn == nil {
+		for _, fn := range r.bodyHooks {
+			fn(r.headers, body)
+		}
+	}
 
 	for k, vals := range r.headers {
 		for _, val := range vals {
@@ -672,26 +1081,72 @@ func (r *Response) Write(w http.ResponseWriter) error {
 		}
 	}
 	for _, cookie := range r.cookies {
-		http.SetCookie(w, cookie)
+		if cookie.cond != nil && !cookie.cond() {
+			continue
+		}
+		if v := cookie.String(); v != "" {
+			w.Header().Add("Set-Cookie", v)
+		}
 	}
-
-	body := r.rawBody
-	if r.jsonBody != nil {
-		b, err := json.Marshal(r.jsonBody)
-		if err != nil {
-			return err
+	if r.bodyFn == nil && r.handler == nil && len(r.trailers) == 0 && bodyAllowed(r.StatusCode) &&
+		w.Header().Get("Content-Length") == "" && w.Header().Get("Transfer-Encoding") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	announced := make(map[string]bool, len(r.trailers))
+	if !r.omitBody {
+		for key := range r.trailers {
+			w.Header().Add("Trailer", key)
+			announced[key] = true
 		}
-		body = b
 	}
-	w.WriteHeader(r.StatusCode)
+	cw := &countingWriter{ResponseWriter: w}
+	defer func() {
+		r.bytesWritten = cw.n
+	}()
+	if r.handler != nil {
+		r.handler.ServeHTTP(cw, r.request)
+		if cw.status != 0 {
+			r.StatusCode = cw.status
+		}
+		return nil
+	}
+	cw.WriteHeader(r.StatusCode)
+	if r.omitBody {
+		return marshalErr
+	}
 	if r.bodyFn != nil {
-		return r.bodyFn(w)
+		if err := r.bodyFn(cw); err != nil {
+			return err
+		}
+		r.writeTrailers(w, announced)
+		return nil
 	}
-	if _, err := w.Write(body); err != nil {
-		return err
+	if len(body) > 0 {
+		if _, err := cw.Write(body); err != nil {
+			return err
+		}
 	}
+	r.writeTrailers(w, announced)
 
-	return nil
+	return marshalErr
+}
+
+// writeTrailers sets the trailer fields on w after the body has been written. Trailers that have not
+// been announced in the "Trailer" header are set with the http.TrailerPrefix.
+func (r *Response) writeTrailers(w http.ResponseWriter, announced map[string]bool) {
+	for key, values := range r.trailers {
+		name := key
+		if !announced[key] && !headerContainsToken(w.Header(), "Trailer", key) {
+			name = http.TrailerPrefix + key
+		}
+		w.Header()[name] = values
+	}
+}
+
+// BytesWritten returns the number of body bytes written by Write.
+// It is only meaningful after the response has been written, e.g. in an AfterWrite function.
+func (r *Response) BytesWritten() int {
+	return r.bytesWritten
 }
 
 // AfterWrite adds a function to be called after the response is written.
@@ -699,3 +1154,61 @@ func (r *Response) AfterWrite(fn func()) *Response {
 	r.afterWrite = append(r.afterWrite, fn)
 	return r
 }
+
+// bodyAllowed reports whether a response with the given status code may have a body.
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// countingWriter is a http.ResponseWriter that counts the number of bytes written to the body
+// and records the status code.
+type countingWriter struct {
+	http.ResponseWriter
+	n      int
+	status int
+}
+
+func (w *countingWriter) WriteHeader(statusCode int) {
+	if w.status == 0 && statusCode >= 200 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *countingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.n += n
+	return n, err
+}
+
+// Flush flushes the underlying writer if it supports flushing.
+func (w *countingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter for use with http.ResponseController.
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// responseCookie is a cookie to be set by a response.
+type responseCookie struct {
+	cookie      *http.Cookie
+	partitioned bool
+	cond        func() bool
+}
+
+// String returns the serialization of the cookie for use in a Set-Cookie header,
+// or an empty string if the cookie is invalid.
+func (c responseCookie) String() string {
+	v := c.cookie.String()
+	if v != "" && c.partitioned {
+		v += "; Partitioned"
+	}
+	return v
+}
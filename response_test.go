@@ -0,0 +1,521 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponse_Close(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	if err := Respond().Unauthorized().Close().Write(w); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := w.Header().Get("Connection"); got != "close" {
+		t.Errorf("Expected Connection header close, got %s", got)
+	}
+	if w.Code != 401 {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestResponse_BytesWritten(t *testing.T) {
+	r := Respond().Text("hello")
+	if err := r.Write(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.BytesWritten() != 5 {
+		t.Errorf("Expected 5 bytes written, got %d", r.BytesWritten())
+	}
+
+	r = Respond().BodyFn("text/plain", func(w io.Writer) error {
+		_, err := w.Write([]byte("streamed"))
+		return err
+	})
+	if err := r.Write(httptest.NewRecorder()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.BytesWritten() != 8 {
+		t.Errorf("Expected 8 bytes written, got %d", r.BytesWritten())
+	}
+}
+
+func TestResponse_NegotiatedLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"de-CH, de;q=0.9, en;q=0.8", "de"},
+		{"fr;q=0.5, en-US", "en"},
+		{"en", "en"},
+		{"", "de"},
+		{"fr", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Language", tt.acceptLanguage)
+		c, _ := newTestContext(req)
+		w := httptest.NewRecorder()
+
+		if err := Respond().NegotiatedLanguage(c, "de", "en").Text("hi").Write(w); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := w.Header().Get("Content-Language"); got != tt.expected {
+			t.Errorf("Expected Content-Language %q for %q, got %q", tt.expected, tt.acceptLanguage, got)
+		}
+	}
+}
+
+func TestResponse_CookieSameSite(t *testing.T) {
+	w := httptest.NewRecorder()
+	chips := &http.Cookie{Name: "chips", Value: "1", Path: "/", SameSite: http.SameSiteNoneMode}
+	err := Respond().
+		Cookie("session", "abc", 3600, "", "", true, true).
+		CookieWithSameSite("embed", "xyz", 0, "/", "", true, false, http.SameSiteNoneMode).
+		CookiePartitioned(chips).
+		Write(w)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cookies := w.Header().Values("Set-Cookie")
+	if len(cookies) != 3 {
+		t.Fatalf("Expected 3 cookies, got %v", cookies)
+	}
+	if strings.Contains(cookies[0], "SameSite") {
+		t.Errorf("Expected no SameSite attribute by default, got %s", cookies[0])
+	}
+	if !strings.Contains(cookies[1], "SameSite=None") {
+		t.Errorf("Expected SameSite=None, got %s", cookies[1])
+	}
+	if !strings.HasSuffix(cookies[2], "; Partitioned") || !strings.Contains(cookies[2], "Secure") {
+		t.Errorf("Expected secure partitioned cookie, got %s", cookies[2])
+	}
+	if chips.Secure {
+		t.Errorf("Expected the caller's cookie to be unchanged")
+	}
+}
+
+func TestResponse_VaryBy(t *testing.T) {
+	w := httptest.NewRecorder()
+	res := Respond().
+		Vary("Accept").
+		VaryBy("Accept-Language", "accept").
+		CacheControl("public, max-age=60").
+		VaryBy("Accept-Encoding").
+		Text("hello")
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if vary := w.Header().Get("Vary"); vary != "Accept, Accept-Language, Accept-Encoding" {
+		t.Errorf("Expected accumulated Vary header, got %s", vary)
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Errorf("Expected Cache-Control header to be kept, got %s", cc)
+	}
+}
+
+func TestResponse_WeakETag(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Respond().WeakETag("abc").Text("hello").Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if etag := w.Header().Get("ETag"); etag != `W/"abc"` {
+		t.Errorf("Expected weak ETag, got %s", etag)
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestResponse_Stream(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader("streamed content")}
+	w := httptest.NewRecorder()
+	res := Respond().Stream("text/plain", body)
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Body.String() != "streamed content" {
+		t.Errorf("Expected streamed body, got %s", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain" {
+		t.Errorf("Expected Content-Type text/plain, got %s", ct)
+	}
+	if res.BytesWritten() != len("streamed content") {
+		t.Errorf("Expected %d bytes written, got %d", len("streamed content"), res.BytesWritten())
+	}
+	if !body.closed {
+		t.Errorf("Expected reader to be closed")
+	}
+}
+
+func TestResponse_Stream_HeadClosesReader(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader("streamed content")}
+	s := NewServer()
+	s.HEAD("/file", func(c *Context) *Response {
+		return Respond().Stream("text/plain", body)
+	})
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("HEAD", "/file", nil))
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected no body, got %s", w.Body.String())
+	}
+	if !body.closed {
+		t.Errorf("Expected reader to be closed")
+	}
+}
+
+func TestResponse_Hijacked(t *testing.T) {
+	w := httptest.NewRecorder()
+	called := false
+	res := Respond().
+		Header("X-Test", "value").
+		Text("hello").
+		Hijacked().
+		AfterWrite(func() { called = true })
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Body.Len() != 0 || len(w.Header()) != 0 {
+		t.Errorf("Expected nothing to be written, got headers %v and body %s", w.Header(), w.Body.String())
+	}
+	if !called {
+		t.Errorf("Expected AfterWrite function to be called")
+	}
+}
+
+func TestResponse_AddHeaderAndHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	res := Respond().
+		Link(`</a>; rel="a"`).
+		AddHeader("Link", `</b>; rel="b"`).
+		Header("X-Keep", "keep").
+		Header("X-Replace", "old").
+		Headers(http.Header{
+			"X-Replace": {"new1", "new2"},
+			"X-Added":   {"added"},
+		}).
+		Text("hello")
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if links := w.Header().Values("Link"); len(links) != 2 || links[1] != `</b>; rel="b"` {
+		t.Errorf("Expected two Link headers, got %v", links)
+	}
+	if v := w.Header().Values("X-Replace"); len(v) != 2 || v[0] != "new1" || v[1] != "new2" {
+		t.Errorf("Expected replaced values, got %v", v)
+	}
+	if w.Header().Get("X-Keep") != "keep" || w.Header().Get("X-Added") != "added" {
+		t.Errorf("Expected kept and added headers, got %v", w.Header())
+	}
+}
+
+func TestResponse_JsonIndent(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Respond().JsonIndent(map[string]string{"html": "<b>"}, "", "  ").SetEscapeHTML(false).Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body := w.Body.String(); body != "{\n  \"html\": \"<b>\"\n}" {
+		t.Errorf("Expected indented unescaped JSON, got %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	if err := Respond().Json(map[string]string{"html": "<b>"}).Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if body := w.Body.String(); body != `{"html":"\u003cb\u003e"}` {
+		t.Errorf("Expected compact escaped JSON, got %s", body)
+	}
+}
+
+func TestResponse_JsonStream(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Respond().JsonStream([]int{1, 2, 3}).Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Expected json content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if body := w.Body.String(); body != "[1,2,3]\n" {
+		t.Errorf("Expected streamed JSON, got %q", body)
+	}
+
+	w = httptest.NewRecorder()
+	if err := Respond().JsonStream(make(chan int)).Write(w); err == nil {
+		t.Errorf("Expected encoding error")
+	}
+}
+
+func TestResponse_ConditionalCookie(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusBadRequest} {
+		res := Respond().Status(status)
+		res.ConditionalCookie(func() bool { return res.StatusCode < 400 }, &http.Cookie{Name: "visited", Value: "1"})
+		w := httptest.NewRecorder()
+		if err := res.Write(w); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		cookies := w.Result().Cookies()
+		if status == http.StatusOK && (len(cookies) != 1 || cookies[0].Name != "visited") {
+			t.Errorf("Expected cookie for status %d, got %v", status, cookies)
+		}
+		if status == http.StatusBadRequest && len(cookies) != 0 {
+			t.Errorf("Expected no cookie for status %d, got %v", status, cookies)
+		}
+	}
+}
+
+func TestResponse_StatusHelpers(t *testing.T) {
+	tests := []struct {
+		res    *Response
+		status int
+	}{
+		{Respond().Accepted(), http.StatusAccepted},
+		{Respond().PartialContent(), http.StatusPartialContent},
+		{Respond().Gone(), http.StatusGone},
+		{Respond().URITooLong(), http.StatusRequestURITooLong},
+		{Respond().UnprocessableEntity(), http.StatusUnprocessableEntity},
+		{Respond().TooManyRequests(), http.StatusTooManyRequests},
+		{Respond().ServiceUnavailable(), http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		if tt.res.StatusCode != tt.status {
+			t.Errorf("Expected status %d, got %d", tt.status, tt.res.StatusCode)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	err := Respond().UnprocessableEntity(RequireNotEmpty("name", "", nil)).Write(w)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"name"`) {
+		t.Errorf("Expected validation error body, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestResponse_Redirect(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("POST", "/login", nil))
+	res := Respond().Redirect(c, "/home", http.StatusSeeOther)
+	if res.StatusCode != http.StatusSeeOther || res.headers.Get("Location") != "/home" || res.headers.Get("HX-Redirect") != "" {
+		t.Errorf("Expected 303 redirect to /home, got %d %v", res.StatusCode, res.headers)
+	}
+
+	req := httptest.NewRequest("POST", "/login", nil)
+	req.Header.Set("HX-Request", "true")
+	c, _ = newTestContext(req)
+	res = Respond().Redirect(c, "/home", http.StatusSeeOther)
+	if res.StatusCode != http.StatusOK || res.headers.Get("HX-Redirect") != "/home" || res.headers.Get("Location") != "" {
+		t.Errorf("Expected 200 with HX-Redirect to /home, got %d %v", res.StatusCode, res.headers)
+	}
+}
+
+func TestResponse_DeleteCookie(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Respond().DeleteCookie("session", "", "example.com").Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	cookie := w.Header().Get("Set-Cookie")
+	for _, attr := range []string{"session=;", "Path=/", "Domain=example.com", "Expires=Thu, 01 Jan 1970 00:00:00 GMT", "Max-Age=0"} {
+		if !strings.Contains(cookie, attr) {
+			t.Errorf("Expected cookie to contain %s, got %s", attr, cookie)
+		}
+	}
+}
+
+func TestResponse_BodyFnChecksummed(t *testing.T) {
+	body := strings.Repeat("chunk of data\n", 1000)
+	w := httptest.NewRecorder()
+	res := Respond().BodyFnChecksummed("text/plain", "Content-Digest", func(w io.Writer) error {
+		_, err := io.Copy(w, strings.NewReader(body))
+		return err
+	})
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	result := w.Result()
+	if result.Header.Get("Trailer") != "Content-Digest" {
+		t.Errorf("Expected trailer to be announced, got %s", result.Header.Get("Trailer"))
+	}
+	if w.Body.String() != body {
+		t.Errorf("Expected streamed body")
+	}
+	if digest, expected := result.Trailer.Get("Content-Digest"), formatDigest(DigestAlgorithmSHA256, []byte(body)); digest != expected {
+		t.Errorf("Expected trailer %s, got %s", expected, digest)
+	}
+}
+
+func TestResponse_Unavailable(t *testing.T) {
+	w := httptest.NewRecorder()
+	if err := Respond().Unavailable(1500*time.Millisecond, "down for maintenance").Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected Retry-After 2, got %s", w.Header().Get("Retry-After"))
+	}
+	if w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("Expected problem json content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if body := w.Body.String(); body != `{"title":"Service Unavailable","status":503,"detail":"down for maintenance"}` {
+		t.Errorf("Expected problem details body, got %s", body)
+	}
+}
+
+func TestResponse_Write_ContentLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		res      *Response
+		expected string
+	}{
+		{"json", Respond().Json(map[string]string{"name": "alice"}), "16"},
+		{"text", Respond().Text("hello"), "5"},
+		{"empty", Respond(), "0"},
+		{"no content", Respond().NoContent(), ""},
+		{"stream", Respond().BodyFn("text/plain", func(w io.Writer) error {
+			_, err := io.WriteString(w, "hello")
+			return err
+		}), ""},
+		{"chunked", Respond().TransferEncoding(TransferEncodingChunked).Text("hello"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := tt.res.Write(w); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if cl := w.Header().Get("Content-Length"); cl != tt.expected {
+				t.Errorf("Expected Content-Length %q, got %q", tt.expected, cl)
+			}
+		})
+	}
+}
+
+func TestResponse_WebSub(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := Respond().WebSub("https://hub.example.com/", "https://example.com/feed.xml").Body("application/atom+xml", []byte("<feed/>")).Write(w)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	links := w.Header().Values("Link")
+	if len(links) != 2 || links[0] != `<https://hub.example.com/>; rel="hub"` || links[1] != `<https://example.com/feed.xml>; rel="self"` {
+		t.Errorf("Expected hub and self links, got %v", links)
+	}
+}
+
+func TestResponse_SetTrailer(t *testing.T) {
+	s := NewServer()
+	s.GET("/buffered", func(c *Context) *Response {
+		return Respond().Text("hello").SetTrailer("X-Checksum", "abc")
+	})
+	s.GET("/streamed", func(c *Context) *Response {
+		res := Respond().Trailer("X-Checksum")
+		return res.BodyFn("text/plain", func(w io.Writer) error {
+			if _, err := io.WriteString(w, "hello"); err != nil {
+				return err
+			}
+			res.SetTrailer("X-Checksum", "def")
+			return nil
+		})
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	for path, expected := range map[string]string{"/buffered": "abc", "/streamed": "def"} {
+		res, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatalf("Expected response, got %v", err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		if string(body) != "hello" {
+			t.Errorf("%s: Expected body hello, got %s", path, body)
+		}
+		if v := res.Trailer.Get("X-Checksum"); v != expected {
+			t.Errorf("%s: Expected trailer %s, got %s", path, expected, v)
+		}
+	}
+}
+
+func TestResponse_Write_MarshalError(t *testing.T) {
+	s := NewServer()
+	s.GET("/broken", func(c *Context) *Response {
+		return Respond().Json(struct{ Updates chan int }{make(chan int)})
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/broken")
+	if err != nil {
+		t.Fatalf("Expected response, got %v", err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", res.StatusCode)
+	}
+	if string(body) != `{"code":"InternalServerError","message":"unable to encode response body"}` {
+		t.Errorf("Expected ErrorDto body, got %s", body)
+	}
+}
+
+func TestResponse_PageLinks(t *testing.T) {
+	res := Respond().PageLinks("https://example.com/users?sort=name", 3, 10, 95)
+	expected := []string{
+		`<https://example.com/users?page=1&per_page=10&sort=name>; rel="first"`,
+		`<https://example.com/users?page=2&per_page=10&sort=name>; rel="prev"`,
+		`<https://example.com/users?page=4&per_page=10&sort=name>; rel="next"`,
+		`<https://example.com/users?page=10&per_page=10&sort=name>; rel="last"`,
+	}
+	if links := res.headers.Values("Link"); strings.Join(links, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected links %v, got %v", expected, links)
+	}
+
+	links := Respond().PageLinks("/users", 1, 10, 5).headers.Values("Link")
+	if len(links) != 2 || !strings.Contains(links[0], `rel="first"`) || !strings.Contains(links[1], `rel="last"`) {
+		t.Errorf("Expected only first and last links for a single page, got %v", links)
+	}
+}
+
+func TestResponse_BodyFnChecksummed_Server(t *testing.T) {
+	body := strings.Repeat("chunk of data\n", 1000)
+	s := NewServer()
+	s.GET("/download", func(c *Context) *Response {
+		return Respond().BodyFnChecksummed("text/plain", "Content-Digest", func(w io.Writer) error {
+			_, err := io.Copy(w, strings.NewReader(body))
+			return err
+		})
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	b, _ := io.ReadAll(res.Body)
+	if string(b) != body {
+		t.Errorf("Expected streamed body")
+	}
+	if digest, expected := res.Trailer.Get("Content-Digest"), formatDigest(DigestAlgorithmSHA256, []byte(body)); digest != expected {
+		t.Errorf("Expected trailer %s, got %s", expected, digest)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// MethodAny is the method of routes that match all methods, e.g. handlers added with Server.Mount.
+const MethodAny = "*"
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Method is the HTTP method of the route.
+	Method string
+	// Path is the path pattern of the route, including the path of its group.
+	Path string
+	// Middleware is the number of middleware applied to the route, including server and group middleware.
+	Middleware int
+}
+
+// routeTable records the routes registered on a Server and its groups.
+type routeTable struct {
+	routes []RouteInfo
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{
+		routes: make([]RouteInfo, 0),
+	}
+}
+
+func (t *routeTable) add(method, path string, middleware int) {
+	t.routes = append(t.routes, RouteInfo{Method: method, Path: path, Middleware: middleware})
+}
+
+// methods returns the distinct methods of all recorded routes, except MethodAny.
+func (t *routeTable) methods() []string {
+	methods := make([]string, 0)
+	for _, r := range t.routes {
+		if r.Method != MethodAny && !slices.Contains(methods, r.Method) {
+			methods = append(methods, r.Method)
+		}
+	}
+	return methods
+}
+
+// allowedMethods returns the methods of the recorded routes that match the path of the request,
+// in canonical order. GET routes also match HEAD requests. OPTIONS is always allowed if any method matches.
+func (t *routeTable) allowedMethods(mux *http.ServeMux, r *http.Request) []string {
+	allowed := make([]string, 0)
+	probe := *r
+	for _, method := range t.methods() {
+		probe.Method = method
+		if _, pattern := mux.Handler(&probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	if len(allowed) == 0 {
+		return allowed
+	}
+	if slices.Contains(allowed, http.MethodGet) && !slices.Contains(allowed, http.MethodHead) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if !slices.Contains(allowed, http.MethodOptions) {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	slices.SortStableFunc(allowed, func(a, b string) int {
+		return methodRank(a) - methodRank(b)
+	})
+	return allowed
+}
+
+var canonicalMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// methodRank returns the position of method in canonicalMethods. Other methods are ranked last.
+func methodRank(method string) int {
+	if i := slices.Index(canonicalMethods, method); i >= 0 {
+		return i
+	}
+	return len(canonicalMethods)
+}
+
+// fallbackHandler handles requests for which no route matches the request method.
+// OPTIONS requests are answered with the allowed methods, other methods are passed to methodNotAllowed,
+// or receive a 405 response if it is nil.
+func fallbackHandler(allowed []string, methodNotAllowed Handler) Handler {
+	allow := strings.Join(allowed, ", ")
+	return func(c *Context) *Response {
+		if c.r.Method == http.MethodOptions {
+			return Respond().NoContent().Allow(allowed...)
+		}
+		if methodNotAllowed != nil {
+			res := methodNotAllowed(c)
+			if res != nil && res.headers.Get("Allow") == "" {
+				res.Allow(allowed...)
+			}
+			return res
+		}
+		return respondError(http.StatusMethodNotAllowed, "MethodNotAllowed", "method "+c.r.Method+" is not allowed, use one of "+allow).
+			Allow(allowed...)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+// SecurityConfig configures the headers set by SecurityHeadersMiddleware. Empty values are not set.
+type SecurityConfig struct {
+	// StrictTransportSecurity is the value of the Strict-Transport-Security header, e.g. "max-age=63072000".
+	StrictTransportSecurity string
+	// ContentTypeNosniff sets the X-Content-Type-Options header to "nosniff".
+	ContentTypeNosniff bool
+	// XFrameOptions is the value of the X-Frame-Options header, e.g. XFrameOptionsDENY.
+	XFrameOptions string
+	// ContentSecurityPolicy is the value of the Content-Security-Policy header.
+	ContentSecurityPolicy string
+	// ReferrerPolicy is the value of the Referrer-Policy header, e.g. "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+}
+
+// DefaultSecurityConfig returns a SecurityConfig with conservative defaults for APIs.
+func DefaultSecurityConfig() SecurityConfig {
+	return SecurityConfig{
+		StrictTransportSecurity: "max-age=63072000; includeSubDomains",
+		ContentTypeNosniff:      true,
+		XFrameOptions:           XFrameOptionsDENY,
+		ContentSecurityPolicy:   "default-src 'none'; frame-ancestors 'none'",
+		ReferrerPolicy:          "no-referrer",
+	}
+}
+
+// SecurityHeadersMiddleware sets the security headers configured in cfg on every response.
+// Headers that the handler already set on the response are kept, so handlers can override them.
+func SecurityHeadersMiddleware(cfg SecurityConfig) Middleware {
+	return func(c *Context, next Handler) *Response {
+		r := next(c)
+		if cfg.StrictTransportSecurity != "" && r.headers.Get("Strict-Transport-Security") == "" {
+			r.StrictTransportSecurity(cfg.StrictTransportSecurity)
+		}
+		if cfg.ContentTypeNosniff && r.headers.Get("X-Content-Type-Options") == "" {
+			r.XContentTypeOptions()
+		}
+		if cfg.XFrameOptions != "" && r.headers.Get("X-Frame-Options") == "" {
+			r.XFrameOptions(cfg.XFrameOptions)
+		}
+		if cfg.ContentSecurityPolicy != "" && r.headers.Get("Content-Security-Policy") == "" {
+			r.ContentSecurityPolicy(cfg.ContentSecurityPolicy)
+		}
+		if cfg.ReferrerPolicy != "" && r.headers.Get("Referrer-Policy") == "" {
+			r.ReferrerPolicy(cfg.ReferrerPolicy)
+		}
+		return r
+	}
+}
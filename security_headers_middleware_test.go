@@ -0,0 +1,44 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	s := NewServer().Use(SecurityHeadersMiddleware(DefaultSecurityConfig()))
+	s.GET("/api", func(c *Context) *Response {
+		return Respond().Json(map[string]string{"status": "ok"})
+	})
+	s.GET("/embed", func(c *Context) *Response {
+		return Respond().XFrameOptions(XFrameOptionsSAMEORIGIN).Html("<p>embed</p>")
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api", nil))
+	expected := map[string]string{
+		"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+		"Referrer-Policy":           "no-referrer",
+	}
+	for k, v := range expected {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("Expected %s: %s, got %s", k, v, got)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/embed", nil))
+	if got := w.Header().Values("X-Frame-Options"); len(got) != 1 || got[0] != "SAMEORIGIN" {
+		t.Errorf("Expected handler X-Frame-Options to be kept, got %v", got)
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("Expected other security headers to be set")
+	}
+}
@@ -5,8 +5,13 @@
 package srv
 
 import (
+	"html/template"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -18,21 +23,23 @@ type Server struct {
 	MaxMultipartMemory int64
 	middleware         []Middleware
 	mux                *http.ServeMux
+	routes             *routeTable
 	contextConfig      *contextConfig
+	notFound           Handler
+	methodNotAllowed   Handler
+	printRoutes        bool
+	maxQueryLength     int
+	readHeaderTimeout  time.Duration
+	maxHeaderBytes     int
 }
 
 // NewServer creates a new Server with a new ServeMux.
 func NewServer() *Server {
 	return &Server{
-		middleware: make([]Middleware, 0),
-		mux:        http.NewServeMux(),
-		contextConfig: &contextConfig{
-			maxMultipartMemory: DefaultMaxMultipartMemory,
-			ipResolver: NewIPResolver([]string{
-				"X-Forwarded-For",
-				"Forwarded",
-			}, false),
-		},
+		middleware:    make([]Middleware, 0),
+		mux:           http.NewServeMux(),
+		routes:        newRouteTable(),
+		contextConfig: newContextConfig(),
 	}
 }
 
@@ -41,6 +48,15 @@ func (s *Server) SetMaxMultipartMemory(max int64) *Server {
 	return s
 }
 
+// SetMaxRequestBody limits the size of request bodies read through the Context, e.g. by BindJSON,
+// GetRawData, Body and FormValues. A value of 0 or less disables the limit, which is the default.
+// Binding a body that exceeds the limit results in 413 Request Entity Too Large.
+// Use MaxRequestBodyMiddleware to override the limit for individual routes.
+func (s *Server) SetMaxRequestBody(max int64) *Server {
+	s.contextConfig.maxRequestBody = max
+	return s
+}
+
 func (s *Server) SetRemoteIPHeaders(headers ...string) *Server {
 	s.contextConfig.ipResolver.RemoteIPHeaders = headers
 	return s
@@ -51,17 +67,111 @@ func (s *Server) SetTrustRemoteIdHeaders(trust bool) *Server {
 	return s
 }
 
-// Group creates a new Group with the given path.
+// SetTrustedProxyHops sets the number of proxies in front of the server, e.g. 1 for a single load balancer.
+// Context.ClientIP then returns the address hops proxies from the right of the X-Forwarded-For chain,
+// which can't be forged by clients, instead of trusting the whole chain. A value of 0 disables hop
+// counting, which is the default. SetTrustedProxyHops panics if hops is negative.
+func (s *Server) SetTrustedProxyHops(hops int) *Server {
+	if hops < 0 {
+		panic("hops must not be negative")
+	}
+	s.contextConfig.ipResolver.TrustedHops = hops
+	return s
+}
+
+// SetMaxQueryLength limits the length of the raw query string of requests. Requests with longer query
+// strings are rejected with 414 URI Too Long before they are routed. A value of 0 or less disables the limit,
+// which is the default.
+func (s *Server) SetMaxQueryLength(max int) *Server {
+	s.maxQueryLength = max
+	return s
+}
+
+// SetReadHeaderTimeout sets the time allowed to read the request headers in ListenAndServe, which
+// mitigates slow header attacks. A value of 0 or less disables the timeout, which is the default.
+func (s *Server) SetReadHeaderTimeout(timeout time.Duration) *Server {
+	s.readHeaderTimeout = timeout
+	return s
+}
+
+// SetMaxHeaderBytes sets the maximum size of the request line and headers in ListenAndServe. Requests
+// with larger headers are rejected with 431 Request Header Fields Too Large. A value of 0 or less uses
+// http.DefaultMaxHeaderBytes.
+func (s *Server) SetMaxHeaderBytes(max int) *Server {
+	s.maxHeaderBytes = max
+	return s
+}
+
+// SetTemplates registers the templates used by Context.Template.
+func (s *Server) SetTemplates(tmpl *template.Template) *Server {
+	s.contextConfig.templates = tmpl
+	return s
+}
+
+// SetPageSizeParam sets the query parameter holding the number of items per page that is read by
+// Context.Pagination and set in the links of Response.Page, e.g. "per_page". It defaults to PageSizeParam.
+// SetPageSizeParam panics if name is empty.
+func (s *Server) SetPageSizeParam(name string) *Server {
+	if name == "" {
+		panic("name must not be empty")
+	}
+	s.contextConfig.pageSizeParam = name
+	return s
+}
+
+// SetStrictSparseFields makes Response.SparseJson respond with 400 Bad Request if unknown fields are requested.
+// By default, unknown fields are ignored.
+func (s *Server) SetStrictSparseFields(strict bool) *Server {
+	s.contextConfig.strictSparseFields = strict
+	return s
+}
+
+// SetErrorMapper sets the function turning errors returned by ErrorHandlers registered with H into responses,
+// e.g. to map errors of the service layer to status codes. It defaults to DefaultErrorMapper, which can be
+// called by mapper for errors it doesn't handle.
+func (s *Server) SetErrorMapper(mapper ErrorMapper) *Server {
+	s.contextConfig.errorMapper = mapper
+	return s
+}
+
+// SetErrorPage registers a template that is rendered instead of the JSON body for responses with
+// the given status code, when the client prefers HTML over JSON according to its Accept header.
+// The template is executed with ErrorPageData.
+func (s *Server) SetErrorPage(status int, tmpl *template.Template) *Server {
+	s.contextConfig.errorPages[status] = tmpl
+	return s
+}
+
+// NotFound sets the handler for requests that don't match any route.
+// By default, a plain text 404 response is written.
+func (s *Server) NotFound(handler Handler) *Server {
+	s.notFound = handler
+	return s
+}
+
+// MethodNotAllowed sets the handler for requests that match the path of a route, but not its method.
+// The Allow header listing the methods of the matching routes is added to the response, unless the
+// handler sets it. By default, a 405 response with an ErrorDto is returned.
+func (s *Server) MethodNotAllowed(handler Handler) *Server {
+	s.methodNotAllowed = handler
+	return s
+}
+
+// Group creates a new Group with the given path. The group uses the middleware added to the Server so far,
+// followed by the given middleware. Middleware added to the Server afterwards doesn't apply to the group.
 func (s *Server) Group(path string, middleware ...Middleware) *Group {
 	return &Group{
 		basePath:      path,
 		mux:           s.mux,
-		middleware:    append(s.middleware[:], middleware...),
+		routes:        s.routes,
+		middleware:    append(slices.Clone(s.middleware), middleware...),
 		contextConfig: s.contextConfig,
 	}
 }
 
-// Use adds middleware to the Server.
+// Use adds middleware to the Server. It applies to routes and groups added afterwards.
+// Middleware runs in the order it was added: server middleware first, then group middleware,
+// then route middleware, each wrapping the next.
 func (s *Server) Use(middleware ...Middleware) *Server {
 	s.middleware = append(s.middleware, middleware...)
 	return s
@@ -103,31 +213,101 @@ func (s *Server) handleMethod(method, path string, handler Handler, middleware [
 		path = "/"
 	}
 	pattern := method + " " + path
-	s.mux.HandleFunc(pattern, wrap(s.contextConfig, append(s.middleware, middleware...), handler))
+	mw := append(slices.Clone(s.middleware), middleware...)
+	s.mux.HandleFunc(pattern, wrap(s.contextConfig, pattern, mw, handler))
+	s.routes.add(method, path, len(mw))
+}
+
+// Mount serves all requests below prefix with the http.Handler h, e.g. a metrics handler or pprof.
+// The prefix is stripped from the request path before it is passed to h. The server middleware and the
+// given middleware are applied. Headers set by middleware are sent, but h writes status code and body.
+func (s *Server) Mount(prefix string, h http.Handler, middleware ...Middleware) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	stripped := http.StripPrefix(prefix, h)
+	mw := append(slices.Clone(s.middleware), middleware...)
+	s.mux.HandleFunc(prefix+"/", wrap(s.contextConfig, prefix+"/", mw, WrapH(stripped)))
+	s.routes.add(MethodAny, prefix+"/", len(mw))
+}
+
+// Routes returns all registered routes, including the routes of groups, in registration order.
+func (s *Server) Routes() []RouteInfo {
+	return slices.Clone(s.routes.routes)
+}
+
+// SetPrintRoutes enables logging all registered routes when ListenAndServe is called.
+func (s *Server) SetPrintRoutes(print bool) *Server {
+	s.printRoutes = print
+	return s
 }
 
 // ListenAndServe starts the server and listens for incoming requests on the given address.
 func (s *Server) ListenAndServe(address string) error {
-	return http.ListenAndServe(address, s.mux)
+	if s.printRoutes {
+		for _, r := range s.routes.routes {
+			slog.Info("route", "method", r.Method, "path", r.Path, "middleware", r.Middleware)
+		}
+	}
+	return s.httpServer(address).ListenAndServe()
 }
 
+// httpServer creates the http.Server used by ListenAndServe.
+func (s *Server) httpServer(address string) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           s,
+		ReadHeaderTimeout: s.readHeaderTimeout,
+		MaxHeaderBytes:    s.maxHeaderBytes,
+	}
+}
+
+// Handler returns the Server as http.Handler.
 func (s *Server) Handler() http.Handler {
-	return s.mux
+	return s
+}
+
+// ServeHTTP dispatches the request to the matching route. If a route matches the path but not the method,
+// OPTIONS requests are answered with the allowed methods and other requests receive a 405 response,
+// both with an Allow header. The server middleware is applied to these responses.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.maxQueryLength > 0 && len(r.URL.RawQuery) > s.maxQueryLength {
+		res := respondError(http.StatusRequestURITooLong, "URITooLong", "query string must not exceed "+strconv.Itoa(s.maxQueryLength)+" characters")
+		if err := res.Write(w); err != nil {
+			slog.Error("unable to write response", "error", err.Error())
+		}
+		return
+	}
+	if _, pattern := s.mux.Handler(r); pattern != "" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+	allowed := s.routes.allowedMethods(s.mux, r)
+	if len(allowed) == 0 {
+		if s.notFound == nil {
+			s.mux.ServeHTTP(w, r)
+			return
+		}
+		wrap(s.contextConfig, "", s.middleware, s.notFound)(w, r)
+		return
+	}
+	wrap(s.contextConfig, "", s.middleware, fallbackHandler(allowed, s.methodNotAllowed))(w, r)
 }
 
 type Group struct {
 	basePath      string
 	middleware    []Middleware
 	mux           *http.ServeMux
+	routes        *routeTable
 	contextConfig *contextConfig
 }
 
-// Group creates a new Group with the given path.
+// Group creates a new Group with the given path. The nested group uses the middleware of g,
+// followed by the given middleware.
 func (g *Group) Group(path string, middleware ...Middleware) *Group {
 	return &Group{
-		middleware:    append(g.middleware[:], middleware...),
+		middleware:    append(slices.Clone(g.middleware), middleware...),
 		basePath:      g.basePath + path,
 		mux:           g.mux,
+		routes:        g.routes,
 		contextConfig: g.contextConfig,
 	}
 }
@@ -164,19 +344,33 @@ func (g *Group) DELETE(path string, handler Handler, middleware ...Middleware) {
 
 // handleMethod adds a new route for the given method, path, handler, and middleware.
 func (g *Group) handleMethod(method, path string, handler Handler, middleware []Middleware) {
-	g.mux.HandleFunc(method+" "+g.basePath+path, wrap(g.contextConfig, append(g.middleware, middleware...), handler))
+	pattern := method + " " + g.basePath + path
+	mw := append(slices.Clone(g.middleware), middleware...)
+	g.mux.HandleFunc(pattern, wrap(g.contextConfig, pattern, mw, handler))
+	g.routes.add(method, g.basePath+path, len(mw))
 }
 
-func wrap(conf *contextConfig, middleware []Middleware, handler Handler) func(http.ResponseWriter, *http.Request) {
+// wrap adapts handler and its middleware to an http.HandlerFunc. pattern is the route pattern the
+// handler is registered with, or empty if the handler serves unmatched requests.
+func wrap(conf *contextConfig, pattern string, middleware []Middleware, handler Handler) func(http.ResponseWriter, *http.Request) {
 	h := handler
 	if len(middleware) > 0 {
 		h = wrapMiddleware(middleware, handler)
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
-		res := h(NewContext(w, r, conf))
+		c := NewContext(w, r, conf)
+		c.pattern = pattern
+		res := h(c)
+		if c.hijacked {
+			return
+		}
 		if res == nil {
 			panic("received nil response from handler")
 		}
+		if len(conf.errorPages) > 0 {
+			renderErrorPage(conf.errorPages, c, res)
+		}
+		res.omitBody = r.Method == http.MethodHead
 		if err := res.Write(w); err != nil {
 			slog.Error("unable to write response", "error", err.Error())
 		}
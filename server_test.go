@@ -0,0 +1,252 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_AutomaticOptions(t *testing.T) {
+	s := NewServer()
+	s.GET("/users/{id}", func(c *Context) *Response { return Respond().NoContent() })
+	s.Group("/users").DELETE("/{id}", func(c *Context) *Response { return Respond().NoContent() })
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("OPTIONS", "/users/1", nil))
+
+	if w.Code != 204 {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if got := w.Header().Get("Allow"); got != "GET, HEAD, DELETE, OPTIONS" {
+		t.Errorf("Expected Allow header GET, HEAD, DELETE, OPTIONS, got %s", got)
+	}
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	s := NewServer()
+	s.GET("/users", func(c *Context) *Response { return Respond().NoContent() })
+	s.POST("/orders", func(c *Context) *Response { return Respond().NoContent() })
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("PUT", "/users", nil))
+	if w.Code != 405 {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+	if got := w.Header().Get("Allow"); got != "GET, HEAD, OPTIONS" {
+		t.Errorf("Expected Allow header GET, HEAD, OPTIONS, got %s", got)
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/unknown", nil))
+	if w.Code != 404 {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+func TestServer_CustomNotFoundAndMethodNotAllowed(t *testing.T) {
+	s := NewServer().
+		NotFound(func(c *Context) *Response {
+			return Respond().NotFound(ErrorDto{Code: "NotFound", Message: "no route for " + c.Request().URL.Path})
+		}).
+		MethodNotAllowed(func(c *Context) *Response {
+			return Respond().MethodNotAllowed(ErrorDto{Code: "MethodNotAllowed"})
+		})
+	s.GET("/users", func(c *Context) *Response { return Respond().NoContent() })
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/unknown", nil))
+	if w.Code != 404 || w.Body.String() != `{"code":"NotFound","message":"no route for /unknown"}` {
+		t.Errorf("Expected custom 404, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+	if w.Code != 405 || w.Body.String() != `{"code":"MethodNotAllowed"}` {
+		t.Errorf("Expected custom 405, got %d %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Allow"); got != "GET, HEAD, OPTIONS" {
+		t.Errorf("Expected Allow header to be added, got %s", got)
+	}
+}
+
+func TestServer_MethodNotAllowed_AllowFromRouteTable(t *testing.T) {
+	s := NewServer()
+	api := s.Group("/api")
+	api.DELETE("/items/{id}", func(c *Context) *Response { return Respond().NoContent() })
+	api.PUT("/items/{id}", func(c *Context) *Response { return Respond().NoContent() })
+	api.GET("/items/{id}", func(c *Context) *Response { return Respond().NoContent() })
+	api.POST("/items", func(c *Context) *Response { return Respond().NoContent() })
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/api/items/1", nil))
+
+	if w.Code != 405 {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+	if got := w.Header().Get("Allow"); got != "GET, HEAD, PUT, DELETE, OPTIONS" {
+		t.Errorf("Expected Allow header GET, HEAD, PUT, DELETE, OPTIONS, got %s", got)
+	}
+}
+
+func TestServer_Routes(t *testing.T) {
+	noop := func(c *Context, next Handler) *Response { return next(c) }
+	s := NewServer().Use(noop)
+	s.GET("/health", func(c *Context) *Response { return Respond().NoContent() })
+	api := s.Group("/api", noop)
+	api.POST("/users", func(c *Context) *Response { return Respond().NoContent() }, noop)
+
+	routes := s.Routes()
+	expected := []RouteInfo{
+		{Method: "GET", Path: "/health", Middleware: 1},
+		{Method: "POST", Path: "/api/users", Middleware: 3},
+	}
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %+v", len(expected), routes)
+	}
+	for i, e := range expected {
+		if routes[i] != e {
+			t.Errorf("Expected route %+v at position %d, got %+v", e, i, routes[i])
+		}
+	}
+}
+
+func TestServer_Mount(t *testing.T) {
+	h := http.NewServeMux()
+	h.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("path=" + r.URL.Path))
+	})
+	var status, bytes int
+	s := NewServer().Use(func(c *Context, next Handler) *Response {
+		r := next(c).Header("X-Middleware", "yes")
+		return r.AfterWrite(func() {
+			status = r.StatusCode
+			bytes = r.BytesWritten()
+		})
+	})
+	s.Mount("/admin/", h, BasicAuthMiddleware("admin", BasicAuthUsers(map[string]string{"alice": "secret"})))
+
+	req := httptest.NewRequest("GET", "/admin/metrics", nil)
+	req.SetBasicAuth("alice", "secret")
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted || w.Body.String() != "path=/metrics" {
+		t.Errorf("Expected mounted handler to serve stripped path, got %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Middleware") != "yes" {
+		t.Errorf("Expected middleware header to be set")
+	}
+	if status != http.StatusAccepted || bytes != 13 {
+		t.Errorf("Expected status 202 and 13 bytes to be recorded, got %d and %d", status, bytes)
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/admin/metrics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected middleware to reject unauthenticated request, got %d", w.Code)
+	}
+}
+
+func TestServer_SetTrustedProxyHops(t *testing.T) {
+	s := NewServer().SetTrustedProxyHops(1)
+	s.GET("/ip", func(c *Context) *Response {
+		return Respond().Text(c.ClientIP())
+	})
+
+	req := httptest.NewRequest("GET", "/ip", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "6.6.6.6, 203.0.113.7")
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	if w.Body.String() != "203.0.113.7" {
+		t.Errorf("Expected client IP 203.0.113.7, got %s", w.Body.String())
+	}
+}
+
+func TestServer_SetMaxQueryLength(t *testing.T) {
+	s := NewServer().SetMaxQueryLength(16)
+	s.GET("/search", func(c *Context) *Response {
+		return Respond().Text(c.Query("q"))
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/search?q="+strings.Repeat("x", 32), nil))
+	if w.Code != http.StatusRequestURITooLong {
+		t.Errorf("Expected status 414, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/search?q=short", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "short" {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestServer_MiddlewareNoAliasing(t *testing.T) {
+	collect := func(name string) Middleware {
+		return func(c *Context, next Handler) *Response {
+			res := next(c)
+			res.headers.Add("X-Chain", name)
+			return res
+		}
+	}
+	s := NewServer().Use(collect("server1"), collect("server2"), collect("server3"))
+	g := s.Group("/api", collect("group"))
+	handler := func(c *Context) *Response { return Respond().NoContent() }
+	g.GET("/a", handler, collect("a"))
+	g.GET("/b", handler, collect("b"))
+	s.GET("/c", handler, collect("c"))
+	s.GET("/d", handler, collect("d"))
+
+	tests := []struct {
+		path  string
+		chain []string
+	}{
+		{"/api/a", []string{"a", "group", "server3", "server2", "server1"}},
+		{"/api/b", []string{"b", "group", "server3", "server2", "server1"}},
+		{"/c", []string{"c", "server3", "server2", "server1"}},
+		{"/d", []string{"d", "server3", "server2", "server1"}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if chain := w.Header().Values("X-Chain"); strings.Join(chain, ",") != strings.Join(tt.chain, ",") {
+			t.Errorf("Expected middleware %v for %s, got %v", tt.chain, tt.path, chain)
+		}
+	}
+}
+
+func TestServer_HeadOmitsBody(t *testing.T) {
+	s := NewServer()
+	s.GET("/users", func(c *Context) *Response {
+		return Respond().Json([]string{"alice", "bob"})
+	})
+	s.GET("/stream", func(c *Context) *Response {
+		return Respond().BodyFn("text/plain", func(w io.Writer) error {
+			t.Errorf("Expected body function not to be called for HEAD")
+			return nil
+		})
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("HEAD", "/users", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("Expected 200 without body, got %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Length") != "15" {
+		t.Errorf("Expected Content-Length 15, got %s", w.Header().Get("Content-Length"))
+	}
+
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("HEAD", "/stream", nil))
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("Expected 200 without body, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type serverTiming struct {
+	name     string
+	duration time.Duration
+}
+
+func (t serverTiming) String() string {
+	return t.name + ";dur=" + strconv.FormatFloat(float64(t.duration.Microseconds())/1000, 'f', -1, 64)
+}
+
+// ServerTimingMiddleware measures the total time spent in the handler and sends it, together with the
+// sub-timings recorded via Context.Timing, in the Server-Timing header. Metrics already set on the
+// response are preserved.
+func ServerTimingMiddleware() Middleware {
+	return func(c *Context, next Handler) *Response {
+		start := time.Now()
+		r := next(c)
+		total := serverTiming{name: "total", duration: time.Since(start)}
+
+		timings := c.serverTimings()
+		metrics := make([]string, 0, len(timings)+2)
+		if existing := r.headers.Get("Server-Timing"); existing != "" {
+			metrics = append(metrics, existing)
+		}
+		for _, t := range timings {
+			metrics = append(metrics, t.String())
+		}
+		metrics = append(metrics, total.String())
+		return r.ServerTiming(strings.Join(metrics, ", "))
+	}
+}
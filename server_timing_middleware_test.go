@@ -0,0 +1,56 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServerTimingMiddleware(t *testing.T) {
+	s := NewServer().Use(ServerTimingMiddleware())
+	s.GET("/", func(c *Context) *Response {
+		stop := c.Timing("db")
+		time.Sleep(2 * time.Millisecond)
+		stop()
+		return Respond().ServerTiming("cache;desc=miss").NoContent()
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	header := w.Header().Get("Server-Timing")
+	pattern := regexp.MustCompile(`^cache;desc=miss, db;dur=[0-9.]+, total;dur=[0-9.]+$`)
+	if !pattern.MatchString(header) {
+		t.Errorf("Expected Server-Timing with cache, db and total metrics, got %s", header)
+	}
+}
+
+func TestContext_Timing_Concurrent(t *testing.T) {
+	s := NewServer().Use(ServerTimingMiddleware())
+	s.GET("/", func(c *Context) *Response {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Timing("query")()
+			}()
+		}
+		wg.Wait()
+		return Respond().NoContent()
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if n := strings.Count(w.Header().Get("Server-Timing"), "query;dur="); n != 10 {
+		t.Errorf("Expected 10 query timings, got %d", n)
+	}
+}
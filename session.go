@@ -0,0 +1,352 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SessionCookieName is the name of the cookie holding the signed session ID.
+	SessionCookieName = "session"
+	sessionKey        = "srv.session"
+	flashKeyPrefix    = "srv.flash."
+	createdKey        = "srv.session.created"
+	lastSeenKey       = "srv.session.seen"
+)
+
+// DefaultMemorySessionTTL is the time after which the MemorySessionStore created by
+// NewMemorySessionStore evicts sessions that were neither loaded nor saved.
+const DefaultMemorySessionTTL = 24 * time.Hour
+
+// SessionStore persists session values by session ID.
+type SessionStore interface {
+	// Load returns the values of the session with the given ID. It returns false if the session doesn't exist.
+	Load(id string) (map[string]any, bool, error)
+	// Save stores the values of the session with the given ID.
+	Save(id string, values map[string]any) error
+}
+
+// Session holds the values of a client's session. It is not safe for concurrent use.
+type Session struct {
+	id      string
+	isNew   bool
+	changed bool
+	values  map[string]any
+	store   SessionStore
+}
+
+// ID returns the ID of the session.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Get returns the value stored under key.
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set stores value under key. The session is saved when the response is written.
+func (s *Session) Set(key string, value any) {
+	s.values[key] = value
+	s.changed = true
+}
+
+// Delete removes the value stored under key. The session is saved when the response is written.
+func (s *Session) Delete(key string) {
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.changed = true
+	}
+}
+
+// Save persists the session in the store immediately.
+func (s *Session) Save() error {
+	if err := s.store.Save(s.id, s.values); err != nil {
+		return err
+	}
+	s.changed = false
+	return nil
+}
+
+// SessionValue returns the value stored under key in the session if it has type T.
+func SessionValue[T any](s *Session, key string) (T, bool) {
+	v, ok := s.values[key].(T)
+	return v, ok
+}
+
+// SessionConfig configures the session middleware.
+type SessionConfig struct {
+	// Store persists the session values.
+	Store SessionStore
+	// Key signs the session cookie.
+	Key []byte
+	// Secure always marks the session cookie as secure. Otherwise, the cookie is secure if the request
+	// was made over HTTPS, either directly or through a trusted proxy.
+	Secure bool
+	// SameSite is the SameSite attribute of the session cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+	// MaxAge is the lifetime of a session. It is sent as the Max-Age and Expires attributes of the cookie
+	// and enforced on the server. Zero means the session lasts until the browser is closed.
+	MaxAge time.Duration
+	// IdleTimeout ends sessions that were not used for the given duration. Zero disables the timeout.
+	// Sessions are saved on every request if an idle timeout is set.
+	IdleTimeout time.Duration
+}
+
+// SessionMiddleware loads the session identified by a cookie signed with key from store and makes it
+// available through Context.Session. Changed sessions are saved when the handler returns. A session cookie
+// is only set once a new session contains values. Cookies with an invalid signature start a new session.
+func SessionMiddleware(store SessionStore, key []byte) Middleware {
+	return SessionMiddlewareWithConfig(SessionConfig{Store: store, Key: key})
+}
+
+// SessionMiddlewareWithConfig works like SessionMiddleware using the given configuration. Expired sessions
+// start a new session. It panics if no store is configured.
+func SessionMiddlewareWithConfig(cfg SessionConfig) Middleware {
+	if cfg.Store == nil {
+		panic("session store must not be nil")
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+	return func(c *Context, next Handler) *Response {
+		now := time.Now()
+		session, err := loadSession(c, cfg, now)
+		if err != nil {
+			return respondInternalServerError(err)
+		}
+		c.Set(sessionKey, session)
+
+		r := next(c)
+
+		if !session.isNew && cfg.IdleTimeout > 0 {
+			session.Set(lastSeenKey, now.Unix())
+		}
+		if !session.changed {
+			return r
+		}
+		if session.isNew {
+			session.values[createdKey] = now.Unix()
+			session.values[lastSeenKey] = now.Unix()
+		}
+		if err := session.Save(); err != nil {
+			return respondInternalServerError(err)
+		}
+		if session.isNew {
+			cookie := &http.Cookie{
+				Name:     SessionCookieName,
+				Value:    signSessionID(session.id, cfg.Key),
+				Path:     "/",
+				Secure:   cfg.Secure || strings.EqualFold(c.ForwardedInfo().Proto, "https"),
+				HttpOnly: true,
+				SameSite: cfg.SameSite,
+			}
+			if cfg.MaxAge > 0 {
+				cookie.MaxAge = int(cfg.MaxAge.Seconds())
+				cookie.Expires = now.Add(cfg.MaxAge)
+			}
+			r.CookieRaw(cookie)
+		}
+		return r
+	}
+}
+
+// Session returns the session loaded by SessionMiddleware. It panics if the middleware is not used.
+func (c *Context) Session() *Session {
+	v, ok := c.Get(sessionKey)
+	if !ok {
+		panic("no session in context, use SessionMiddleware")
+	}
+	return v.(*Session)
+}
+
+// Flash stores a one-time message under key in the session. It can be read with Flashes, e.g. after a redirect.
+// Flash panics if SessionMiddleware is not used.
+func (c *Context) Flash(key, msg string) {
+	s := c.Session()
+	flashes, _ := SessionValue[[]string](s, flashKeyPrefix+key)
+	s.Set(flashKeyPrefix+key, append(flashes, msg))
+}
+
+// Flashes returns the messages stored under key with Flash and removes them from the session.
+// Flashes panics if SessionMiddleware is not used.
+func (c *Context) Flashes(key string) []string {
+	s := c.Session()
+	flashes, _ := SessionValue[[]string](s, flashKeyPrefix+key)
+	s.Delete(flashKeyPrefix + key)
+	return flashes
+}
+
+func loadSession(c *Context, cfg SessionConfig, now time.Time) (*Session, error) {
+	if cookie, err := c.Cookie(SessionCookieName); err == nil {
+		if id, ok := verifySessionID(cookie, cfg.Key); ok {
+			values, ok, err := cfg.Store.Load(id)
+			if err != nil {
+				return nil, err
+			}
+			if ok && !sessionExpired(values, cfg, now) {
+				return &Session{id: id, values: values, store: cfg.Store}, nil
+			}
+		}
+	}
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{id: id, isNew: true, values: make(map[string]any), store: cfg.Store}, nil
+}
+
+// sessionExpired reports whether the session exceeded the configured lifetime or idle timeout. Sessions
+// without a readable timestamp are expired if the corresponding limit is set.
+func sessionExpired(values map[string]any, cfg SessionConfig, now time.Time) bool {
+	if cfg.MaxAge > 0 {
+		created, ok := sessionTime(values[createdKey])
+		if !ok || now.Sub(created) > cfg.MaxAge {
+			return true
+		}
+	}
+	if cfg.IdleTimeout > 0 {
+		seen, ok := sessionTime(values[lastSeenKey])
+		if !ok || now.Sub(seen) > cfg.IdleTimeout {
+			return true
+		}
+	}
+	return false
+}
+
+// sessionTime converts a Unix timestamp stored in the session to a time. Stores may return the timestamp
+// as another numeric type than it was saved with, e.g. float64 after a round trip through JSON.
+func sessionTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case int64:
+		return time.Unix(t, 0), true
+	case int:
+		return time.Unix(int64(t), 0), true
+	case float64:
+		return time.Unix(int64(t), 0), true
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return time.Unix(n, 0), true
+		}
+	case string:
+		if n, err := strconv.ParseInt(t, 10, 64); err == nil {
+			return time.Unix(n, 0), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func signSessionID(id string, key []byte) string {
+	return id + "." + base64.RawURLEncoding.EncodeToString(computeHMAC(key, []byte(id)))
+}
+
+func verifySessionID(value string, key []byte) (string, bool) {
+	id, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return "", false
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(expected, computeHMAC(key, []byte(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// MemorySessionStore is an in-memory SessionStore. Sessions that were neither loaded nor saved for the
+// TTL of the store are evicted.
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	sessions  map[string]*memorySession
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memorySession struct {
+	values     map[string]any
+	lastAccess time.Time
+}
+
+// NewMemorySessionStore creates a new MemorySessionStore with the DefaultMemorySessionTTL.
+func NewMemorySessionStore() *MemorySessionStore {
+	return NewMemorySessionStoreWithTTL(DefaultMemorySessionTTL)
+}
+
+// NewMemorySessionStoreWithTTL creates a new MemorySessionStore that evicts sessions that were neither
+// loaded nor saved for ttl. It panics if ttl is not positive.
+func NewMemorySessionStoreWithTTL(ttl time.Duration) *MemorySessionStore {
+	if ttl <= 0 {
+		panic("ttl must be positive")
+	}
+	return &MemorySessionStore{
+		ttl:      ttl,
+		sessions: make(map[string]*memorySession),
+		now:      time.Now,
+	}
+}
+
+// Load returns a copy of the values of the session with the given ID.
+func (s *MemorySessionStore) Load(id string) (map[string]any, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.maybeSweep(now)
+	session, ok := s.sessions[id]
+	if !ok || now.Sub(session.lastAccess) >= s.ttl {
+		delete(s.sessions, id)
+		return nil, false, nil
+	}
+	session.lastAccess = now
+	return copyValues(session.values), true, nil
+}
+
+// Save stores a copy of the values of the session with the given ID.
+func (s *MemorySessionStore) Save(id string, values map[string]any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.maybeSweep(now)
+	s.sessions[id] = &memorySession{values: copyValues(values), lastAccess: now}
+	return nil
+}
+
+// maybeSweep evicts all expired sessions at most once per TTL.
+func (s *MemorySessionStore) maybeSweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	for id, session := range s.sessions {
+		if now.Sub(session.lastAccess) >= s.ttl {
+			delete(s.sessions, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+func copyValues(values map[string]any) map[string]any {
+	c := make(map[string]any, len(values))
+	for k, v := range values {
+		c[k] = v
+	}
+	return c
+}
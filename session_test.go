@@ -0,0 +1,246 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSessionTestServer() *Server {
+	s := NewServer().Use(SessionMiddleware(NewMemorySessionStore(), []byte("secret")))
+	s.POST("/login", func(c *Context) *Response {
+		c.Session().Set("user", "alice")
+		return Respond().NoContent()
+	})
+	s.GET("/me", func(c *Context) *Response {
+		user, ok := SessionValue[string](c.Session(), "user")
+		if !ok {
+			return Respond().Unauthorized()
+		}
+		return Respond().Text(user)
+	})
+	return s
+}
+
+func TestSessionMiddleware(t *testing.T) {
+	s := newSessionTestServer()
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/login", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != SessionCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("Expected http only session cookie, got %v", cookies)
+	}
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	if w.Code != 200 || w.Body.String() != "alice" {
+		t.Errorf("Expected session value alice, got %d %s", w.Code, w.Body.String())
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected no cookie for unchanged session, got %v", w.Result().Cookies())
+	}
+}
+
+func TestSessionMiddleware_InvalidSignature(t *testing.T) {
+	s := newSessionTestServer()
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/login", nil))
+	cookie := w.Result().Cookies()[0]
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: cookie.Value + "x"})
+	w = httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	if w.Code != 401 {
+		t.Errorf("Expected tampered session cookie to be rejected, got %d", w.Code)
+	}
+}
+
+func TestContext_Flash(t *testing.T) {
+	s := NewServer().Use(SessionMiddleware(NewMemorySessionStore(), []byte("secret")))
+	s.POST("/items", func(c *Context) *Response {
+		c.Flash("info", "item created")
+		c.Flash("info", "item published")
+		return Respond().NoContent()
+	})
+	s.GET("/items", func(c *Context) *Response {
+		return Respond().Text(strings.Join(c.Flashes("info"), ","))
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("POST", "/items", nil))
+	cookie := w.Result().Cookies()[0]
+
+	get := func() string {
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.AddCookie(cookie)
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if body := get(); body != "item created,item published" {
+		t.Errorf("Expected flash messages, got %s", body)
+	}
+	if body := get(); body != "" {
+		t.Errorf("Expected flash messages to be cleared, got %s", body)
+	}
+}
+
+func TestSessionMiddlewareWithConfig(t *testing.T) {
+	s := NewServer().SetTrustRemoteIdHeaders(true).Use(SessionMiddlewareWithConfig(SessionConfig{
+		Store:    NewMemorySessionStore(),
+		Key:      []byte("secret"),
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   time.Hour,
+	}))
+	s.POST("/login", func(c *Context) *Response {
+		c.Session().Set("user", "alice")
+		return Respond().NoContent()
+	})
+
+	req := httptest.NewRequest("POST", "/login", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected session cookie, got %v", cookies)
+	}
+	if c := cookies[0]; !c.Secure || c.SameSite != http.SameSiteStrictMode || c.MaxAge != 3600 || c.Expires.IsZero() {
+		t.Errorf("Expected secure strict cookie with max age, got %v", c)
+	}
+}
+
+func TestSessionMiddleware_Expired(t *testing.T) {
+	store := NewMemorySessionStore()
+	key := []byte("secret")
+	s := NewServer().Use(SessionMiddlewareWithConfig(SessionConfig{
+		Store:       store,
+		Key:         key,
+		MaxAge:      time.Hour,
+		IdleTimeout: time.Minute,
+	}))
+	s.GET("/me", func(c *Context) *Response {
+		user, ok := SessionValue[string](c.Session(), "user")
+		if !ok {
+			return Respond().Unauthorized()
+		}
+		return Respond().Text(user)
+	})
+
+	now := time.Now()
+	for _, tc := range []struct {
+		name    string
+		created time.Time
+		seen    time.Time
+		code    int
+	}{
+		{"active", now.Add(-30 * time.Minute), now.Add(-30 * time.Second), 200},
+		{"idle", now.Add(-30 * time.Minute), now.Add(-2 * time.Minute), 401},
+		{"max age", now.Add(-2 * time.Hour), now, 401},
+	} {
+		_ = store.Save(tc.name, map[string]any{"user": "alice", createdKey: tc.created.Unix(), lastSeenKey: tc.seen.Unix()})
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: signSessionID(tc.name, key)})
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Errorf("Expected %d for %s session, got %d", tc.code, tc.name, w.Code)
+		}
+	}
+}
+
+func TestMemorySessionStore_Eviction(t *testing.T) {
+	now := time.Now()
+	store := NewMemorySessionStoreWithTTL(time.Minute)
+	store.now = func() time.Time { return now }
+
+	_ = store.Save("a", map[string]any{"k": "v"})
+	_ = store.Save("b", map[string]any{"k": "v"})
+	now = now.Add(30 * time.Second)
+	if _, ok, _ := store.Load("a"); !ok {
+		t.Errorf("Expected session a to be kept")
+	}
+	now = now.Add(45 * time.Second)
+	if _, ok, _ := store.Load("b"); ok {
+		t.Errorf("Expected idle session b to be evicted")
+	}
+	if _, ok, _ := store.Load("a"); !ok {
+		t.Errorf("Expected recently loaded session a to be kept")
+	}
+	now = now.Add(2 * time.Minute)
+	_ = store.Save("c", nil)
+	if len(store.sessions) != 1 {
+		t.Errorf("Expected expired sessions to be swept, got %d sessions", len(store.sessions))
+	}
+}
+
+// jsonSessionStore stores session values as JSON, like stores backed by a database or cache.
+type jsonSessionStore struct {
+	sessions map[string][]byte
+}
+
+func (s *jsonSessionStore) Load(id string) (map[string]any, bool, error) {
+	b, ok := s.sessions[id]
+	if !ok {
+		return nil, false, nil
+	}
+	var values map[string]any
+	return values, true, json.Unmarshal(b, &values)
+}
+
+func (s *jsonSessionStore) Save(id string, values map[string]any) error {
+	b, err := json.Marshal(values)
+	s.sessions[id] = b
+	return err
+}
+
+func TestSessionMiddleware_ExpiredJSONStore(t *testing.T) {
+	store := &jsonSessionStore{sessions: make(map[string][]byte)}
+	key := []byte("secret")
+	s := NewServer().Use(SessionMiddlewareWithConfig(SessionConfig{
+		Store:       store,
+		Key:         key,
+		MaxAge:      time.Hour,
+		IdleTimeout: time.Minute,
+	}))
+	s.GET("/me", func(c *Context) *Response {
+		user, ok := SessionValue[string](c.Session(), "user")
+		if !ok {
+			return Respond().Unauthorized()
+		}
+		return Respond().Text(user)
+	})
+
+	now := time.Now()
+	for _, tc := range []struct {
+		name   string
+		values map[string]any
+		code   int
+	}{
+		{"active", map[string]any{"user": "alice", createdKey: now.Unix(), lastSeenKey: now.Unix()}, 200},
+		{"idle", map[string]any{"user": "alice", createdKey: now.Unix(), lastSeenKey: now.Add(-2 * time.Minute).Unix()}, 401},
+		{"max age", map[string]any{"user": "alice", createdKey: now.Add(-2 * time.Hour).Unix(), lastSeenKey: now.Unix()}, 401},
+		{"no timestamps", map[string]any{"user": "alice"}, 401},
+	} {
+		_ = store.Save(tc.name, tc.values)
+		req := httptest.NewRequest("GET", "/me", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: signSessionID(tc.name, key)})
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Errorf("Expected %d for %s session, got %d", tc.code, tc.name, w.Code)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const signaturePrefix = "sha256="
+
+func computeHMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// SignURL adds an expiry and an HMAC-SHA256 signature to rawURL, which can be verified with
+// Context.VerifySignedURL. The expiry is added as Unix timestamp in the query parameter expParam and
+// the hex encoded signature over path and query in sigParam.
+func SignURL(rawURL string, key []byte, sigParam, expParam string, expires time.Time) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Del(sigParam)
+	q.Set(expParam, strconv.FormatInt(expires.Unix(), 10))
+	u.RawQuery = q.Encode()
+	q.Set(sigParam, hex.EncodeToString(computeHMAC(key, signedURLMessage(u.EscapedPath(), q, sigParam))))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// VerifySignedURL checks the signature and expiry of a URL signed with SignURL. Returns a 403 Forbidden
+// response if the signature is missing or doesn't match the path and query, or if the URL is expired.
+func (c *Context) VerifySignedURL(key []byte, sigParam, expParam string) *Response {
+	q := c.r.URL.Query()
+	sig, err := hex.DecodeString(q.Get(sigParam))
+	if err != nil || len(sig) == 0 || !hmac.Equal(sig, computeHMAC(key, signedURLMessage(c.r.URL.EscapedPath(), q, sigParam))) {
+		return respondError(http.StatusForbidden, "InvalidSignature", "invalid url signature")
+	}
+	exp, err := strconv.ParseInt(q.Get(expParam), 10, 64)
+	if err != nil || time.Now().After(time.Unix(exp, 0)) {
+		return respondError(http.StatusForbidden, "ExpiredSignature", "url signature has expired")
+	}
+	return nil
+}
+
+// signedURLMessage returns the signed part of a URL: the path and the sorted query without sigParam.
+func signedURLMessage(path string, q url.Values, sigParam string) []byte {
+	unsigned := url.Values{}
+	for k, v := range q {
+		if k != sigParam {
+			unsigned[k] = v
+		}
+	}
+	return []byte(path + "?" + unsigned.Encode())
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignature_RoundTrip(t *testing.T) {
+	key := []byte("secret")
+	w := httptest.NewRecorder()
+	if err := Respond().Json(map[string]string{"event": "created"}).Sign("X-Signature", key).Write(w); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sig := w.Header().Get("X-Signature")
+	if !strings.HasPrefix(sig, "sha256=") {
+		t.Fatalf("Expected sha256 signature, got %s", sig)
+	}
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader(w.Body.String()))
+	req.Header.Set("X-Signature", sig)
+	c, _ := newTestContext(req)
+	ok, err := c.VerifySignature("X-Signature", key)
+	if err != nil || !ok {
+		t.Errorf("Expected valid signature, got %v, %v", ok, err)
+	}
+	body, _ := io.ReadAll(c.Request().Body)
+	if string(body) != w.Body.String() {
+		t.Errorf("Expected body to be readable after verification, got %s", body)
+	}
+}
+
+func TestSignature_Tampered(t *testing.T) {
+	key := []byte("secret")
+	w := httptest.NewRecorder()
+	if err := Respond().Text("amount=10").Sign("X-Signature", key).Write(w); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/webhook", strings.NewReader("amount=1000"))
+	req.Header.Set("X-Signature", w.Header().Get("X-Signature"))
+	c, _ := newTestContext(req)
+	ok, err := c.VerifySignature("X-Signature", key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ok {
+		t.Errorf("Expected tampered body to fail verification")
+	}
+}
+
+func TestContext_VerifySignedURL(t *testing.T) {
+	key := []byte("secret")
+	valid, err := SignURL("https://example.com/downloads/report.pdf?user=alice", key, "sig", "exp", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _ := SignURL("https://example.com/downloads/report.pdf", key, "sig", "exp", time.Now().Add(-time.Minute))
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"valid", valid, 0},
+		{"expired", expired, http.StatusForbidden},
+		{"tampered path", strings.Replace(valid, "report.pdf", "secret.pdf", 1), http.StatusForbidden},
+		{"tampered query", strings.Replace(valid, "user=alice", "user=bob", 1), http.StatusForbidden},
+		{"unsigned", "https://example.com/downloads/report.pdf", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestContext(httptest.NewRequest("GET", tt.url, nil))
+			res := c.VerifySignedURL(key, "sig", "exp")
+			if tt.status == 0 && res != nil {
+				t.Errorf("Expected no response, got %d", res.StatusCode)
+			}
+			if tt.status != 0 && (res == nil || res.StatusCode != tt.status) {
+				t.Errorf("Expected status %d, got %v", tt.status, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// SortParam is the query parameter parsed by Context.SortParams.
+const SortParam = "sort"
+
+// SortField is a field to sort by with its direction.
+type SortField struct {
+	Field      string
+	Descending bool
+}
+
+// SortParams parses the SortParam query parameter, e.g. "?sort=name,-created", into sort fields.
+// A leading "-" sorts descending, an optional "+" ascending. Fields that are not in allowed result
+// in a 400 Bad Request response, so the fields can safely be passed on, e.g. to a database query.
+// Returns an empty slice if the parameter is absent.
+func (c *Context) SortParams(allowed ...string) ([]SortField, *Response) {
+	fields := make([]SortField, 0)
+	raw := c.Query(SortParam)
+	if raw == "" {
+		return fields, nil
+	}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		f := SortField{}
+		switch {
+		case strings.HasPrefix(part, "-"):
+			f.Descending = true
+			part = part[1:]
+		case strings.HasPrefix(part, "+"):
+			part = part[1:]
+		}
+		if !slices.Contains(allowed, part) {
+			return nil, respondError(http.StatusBadRequest, "InvalidSort", "cannot sort by '"+part+"'")
+		}
+		f.Field = part
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
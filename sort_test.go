@@ -0,0 +1,35 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestContext_SortParams(t *testing.T) {
+	c, _ := newTestContext(httptest.NewRequest("GET", "/?sort="+url.QueryEscape("name,-created,+id"), nil))
+	fields, res := c.SortParams("name", "created", "id")
+	if res != nil {
+		t.Fatalf("Expected no response, got %d", res.StatusCode)
+	}
+	expected := []SortField{{"name", false}, {"created", true}, {"id", false}}
+	if !slices.Equal(fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, fields)
+	}
+
+	c, _ = newTestContext(httptest.NewRequest("GET", "/?sort=name,-password", nil))
+	if _, res := c.SortParams("name", "created"); res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for disallowed field")
+	}
+
+	c, _ = newTestContext(httptest.NewRequest("GET", "/", nil))
+	if fields, res := c.SortParams("name"); res != nil || len(fields) != 0 {
+		t.Errorf("Expected no sort fields, got %v", fields)
+	}
+}
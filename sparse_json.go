@@ -0,0 +1,139 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SparseFieldsParam is the query parameter that selects the fields returned by Response.SparseJson.
+const SparseFieldsParam = "fields"
+
+// SparseJson sets the response body like Json, but only with the top-level fields requested in the
+// SparseFieldsParam query parameter, e.g. "?fields=id,name". If data is an array, the fields of each element
+// are filtered. Without the parameter, all fields are returned. Unknown fields are ignored, unless strict
+// sparse fields are enabled with Server.SetStrictSparseFields, in which case the response is 400 Bad Request.
+// For structs, a field is unknown if the struct has no such JSON field, even if it is omitted from every
+// element. For other types, a field is unknown if no element has it; empty lists have no unknown fields.
+func (r *Response) SparseJson(c *Context, data any) *Response {
+	fields := sparseFields(c.Query(SparseFieldsParam))
+	if len(fields) == 0 {
+		return r.Json(data)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return r.errorBody(http.StatusInternalServerError, "InternalServerError", err.Error())
+	}
+	filtered, unknown, err := filterFields(b, fields)
+	if err != nil {
+		return r.Json(data)
+	}
+	if known := jsonFieldNames(reflect.TypeOf(data)); known != nil {
+		unknown = slices.DeleteFunc(slices.Clone(fields), func(f string) bool { return known[f] })
+	}
+	if c.conf.strictSparseFields && len(unknown) > 0 {
+		return r.errorBody(http.StatusBadRequest, "InvalidFields", "unknown fields: "+strings.Join(unknown, ", "))
+	}
+	return r.Json(filtered)
+}
+
+func sparseFields(param string) []string {
+	fields := make([]string, 0)
+	for _, f := range strings.Split(param, ",") {
+		if f = strings.TrimSpace(f); f != "" && !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// filterFields removes all top-level fields not in fields from the JSON object or array of objects in b.
+// It returns the requested fields that don't exist in any object. Null elements of an array are kept and
+// if the array contains no objects, no field is reported as missing.
+func filterFields(b []byte, fields []string) (any, []string, error) {
+	seen := make(map[string]bool)
+	objects := 0
+	filter := func(obj map[string]json.RawMessage) map[string]json.RawMessage {
+		if obj == nil {
+			return nil
+		}
+		objects++
+		res := make(map[string]json.RawMessage, len(fields))
+		for _, f := range fields {
+			if v, ok := obj[f]; ok {
+				res[f] = v
+				seen[f] = true
+			}
+		}
+		return res
+	}
+	var result any
+	if trimmed := strings.TrimSpace(string(b)); strings.HasPrefix(trimmed, "[") {
+		var objs []map[string]json.RawMessage
+		if err := json.Unmarshal(b, &objs); err != nil {
+			return nil, nil, err
+		}
+		list := make([]map[string]json.RawMessage, len(objs))
+		for i, obj := range objs {
+			list[i] = filter(obj)
+		}
+		result = list
+	} else {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return nil, nil, err
+		}
+		result = filter(obj)
+	}
+	unknown := make([]string, 0)
+	for _, f := range fields {
+		if objects > 0 && !seen[f] {
+			unknown = append(unknown, f)
+		}
+	}
+	return result, unknown, nil
+}
+
+var jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+// jsonFieldNames returns the names of the JSON fields of t, which may be a struct or a pointer, slice or array
+// of structs. It returns nil if the fields can't be determined from the type, e.g. for maps or types with a
+// custom MarshalJSON method.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct || t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		names[name] = true
+	}
+	return names
+}
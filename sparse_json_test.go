@@ -0,0 +1,88 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponse_SparseJson(t *testing.T) {
+	type user struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	users := []user{{"1", "alice", "alice@example.com"}, {"2", "bob", "bob@example.com"}}
+	s := NewServer()
+	s.GET("/users", func(c *Context) *Response {
+		return Respond().SparseJson(c, users)
+	})
+	s.GET("/users/1", func(c *Context) *Response {
+		return Respond().SparseJson(c, users[0])
+	})
+
+	tests := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/users/1?fields=name,id", http.StatusOK, `{"id":"1","name":"alice"}`},
+		{"/users?fields=name", http.StatusOK, `[{"name":"alice"},{"name":"bob"}]`},
+		{"/users/1?fields=name,unknown", http.StatusOK, `{"name":"alice"}`},
+		{"/users/1", http.StatusOK, `{"id":"1","name":"alice","email":"alice@example.com"}`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("Expected %d %s for %s, got %d %s", tt.status, tt.body, tt.url, w.Code, w.Body.String())
+		}
+	}
+
+	s.SetStrictSparseFields(true)
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/users/1?fields=name,unknown", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown field, got %d", w.Code)
+	}
+}
+
+func TestResponse_SparseJson_StrictLists(t *testing.T) {
+	type user struct {
+		ID       string `json:"id"`
+		Nickname string `json:"nickname,omitempty"`
+	}
+	s := NewServer().SetStrictSparseFields(true)
+	s.GET("/empty", func(c *Context) *Response {
+		return Respond().SparseJson(c, []user{})
+	})
+	s.GET("/nulls", func(c *Context) *Response {
+		return Respond().SparseJson(c, []*user{nil, {ID: "1"}})
+	})
+	s.GET("/maps", func(c *Context) *Response {
+		return Respond().SparseJson(c, []map[string]any{})
+	})
+
+	tests := []struct {
+		url    string
+		status int
+		body   string
+	}{
+		{"/empty?fields=id", http.StatusOK, `[]`},
+		{"/empty?fields=unknown", http.StatusBadRequest, ""},
+		{"/nulls?fields=id,nickname", http.StatusOK, `[null,{"id":"1"}]`},
+		{"/nulls?fields=id,unknown", http.StatusBadRequest, ""},
+		{"/maps?fields=id", http.StatusOK, `[]`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, httptest.NewRequest("GET", tt.url, nil))
+		if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("Expected %d %s for %s, got %d %s", tt.status, tt.body, tt.url, w.Code, w.Body.String())
+		}
+	}
+}
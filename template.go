@@ -0,0 +1,35 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"net/http"
+)
+
+// Template renders the template name of tmpl with data into the response body.
+// The Content-Type header is automatically set to "text/html;charset=UTF-8".
+// The template is rendered into a buffer, so a failing template results in a
+// 500 Internal Server Error instead of a partially written response.
+func (r *Response) Template(tmpl *template.Template, name string, data any) *Response {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		slog.Error("unable to render template", "template", name, "error", err)
+		r.rawBody = nil
+		return r.errorBody(http.StatusInternalServerError, "InternalServerError", "unable to render template")
+	}
+	return r.Html(buf.String())
+}
+
+// Template responds with the template name of the templates registered with Server.SetTemplates.
+// See Response.Template. It panics if no templates are registered.
+func (c *Context) Template(name string, data any) *Response {
+	if c.conf.templates == nil {
+		panic("no templates registered, use Server.SetTemplates")
+	}
+	return Respond().Template(c.conf.templates, name, data)
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"html/template"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext_Template(t *testing.T) {
+	tmpl := template.Must(template.New("hello").Parse(`<p>Hello {{.}}</p>`))
+	s := NewServer().SetTemplates(tmpl)
+	s.GET("/hello", func(c *Context) *Response {
+		return c.Template("hello", "<alice>")
+	})
+
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+	if w.Code != 200 {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Errorf("Expected html content type, got %s", w.Header().Get("Content-Type"))
+	}
+	if w.Body.String() != "<p>Hello &lt;alice&gt;</p>" {
+		t.Errorf("Expected rendered template, got %s", w.Body.String())
+	}
+}
+
+func TestResponse_Template_Error(t *testing.T) {
+	tmpl := template.Must(template.New("user").Parse(`<p>{{.Name}}</p><p>{{.Missing}}</p>`))
+	w := httptest.NewRecorder()
+	res := Respond().Template(tmpl, "user", struct{ Name string }{"alice"})
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if w.Code != 500 {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "alice") {
+		t.Errorf("Expected no partial template output, got %s", w.Body.String())
+	}
+}
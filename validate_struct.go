@@ -0,0 +1,264 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValidateStruct validates v using the rules declared in the "validate" struct tags of its fields.
+// Nested structs and slices are validated recursively. Field paths use the name from the "json" tag
+// if present, e.g. "items[0].name". Returns nil if v is valid or not a struct.
+//
+// Rules are separated by commas, e.g. `validate:"required,min=3,max=50"`. Supported rules:
+//
+//   - required: the value must not be nil, the zero value or an empty slice or map
+//   - min=n, max=n: the length of strings and slices or the value of numbers must be within the bound
+//   - email, url, uuid: the string must be a valid email address, absolute URL or UUID
+//   - oneof=a b c: the value must be one of the space separated options
+//
+// Strings, slices, maps and pointers without the required rule are optional: if they are empty or nil,
+// their other rules are skipped. Numbers are always checked against their bounds.
+//
+// ValidateStruct panics if a tag contains an unknown rule or an invalid parameter. The bind methods of
+// Context skip unknown rules instead, e.g. rules meant for another validation library, and respond with
+// 500 Internal Server Error for invalid parameters.
+func ValidateStruct(v any) *ValidationError {
+	verr, err := validateTags(v, true)
+	if err != nil {
+		panic(err.Error())
+	}
+	return verr
+}
+
+// validateTags validates v like ValidateStruct. Unknown rules result in an error if strict is set and are
+// skipped otherwise. Invalid parameters and rules that don't support the type of a field result in an error.
+func validateTags(v any, strict bool) (*ValidationError, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	sv := structValidator{strict: strict}
+	verr := sv.validateStruct(rv, "", nil)
+	return verr, sv.err
+}
+
+// structValidator validates struct values. The first error caused by an invalid tag is recorded in err.
+type structValidator struct {
+	strict bool
+	err    error
+}
+
+// fieldRules are the parsed rules of a struct field.
+type fieldRules struct {
+	index    int
+	name     string
+	rules    []validateRule
+	required bool
+}
+
+// structRulesCache caches the []fieldRules of struct types.
+var structRulesCache sync.Map
+
+func structRules(rt reflect.Type) []fieldRules {
+	if cached, ok := structRulesCache.Load(rt); ok {
+		return cached.([]fieldRules)
+	}
+	fields := make([]fieldRules, 0, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		rules := parseValidateTag(sf.Tag.Get("validate"))
+		fields = append(fields, fieldRules{
+			index:    i,
+			name:     fieldName(sf),
+			rules:    rules,
+			required: slices.ContainsFunc(rules, func(r validateRule) bool { return r.name == "required" }),
+		})
+	}
+	cached, _ := structRulesCache.LoadOrStore(rt, fields)
+	return cached.([]fieldRules)
+}
+
+func (sv *structValidator) validateStruct(rv reflect.Value, prefix string, prev *ValidationError) *ValidationError {
+	for _, f := range structRules(rv.Type()) {
+		prev = sv.validateValue(rv.Field(f.index), prefix+f.name, f.rules, f.required, prev)
+		if sv.err != nil {
+			return prev
+		}
+	}
+	return prev
+}
+
+func (sv *structValidator) validateValue(fv reflect.Value, path string, rules []validateRule, required bool, prev *ValidationError) *ValidationError {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return Require(path, ValidationCodeRequired, violationMessage(ValidationCodeRequired, path, path+" is required"), !required, prev)
+		}
+		fv = fv.Elem()
+	}
+	if isEmptyValue(fv) {
+		if required {
+			return sv.applyRule(fv, path, validateRule{name: "required"}, prev)
+		}
+		return prev
+	}
+	for _, rule := range rules {
+		prev = sv.applyRule(fv, path, rule, prev)
+		if sv.err != nil {
+			return prev
+		}
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		prev = sv.validateStruct(fv, path+".", prev)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len() && sv.err == nil; i++ {
+			prev = sv.validateValue(fv.Index(i), path+"["+strconv.Itoa(i)+"]", nil, false, prev)
+		}
+	}
+	return prev
+}
+
+func (sv *structValidator) applyRule(fv reflect.Value, path string, rule validateRule, prev *ValidationError) *ValidationError {
+	name, param := rule.name, rule.param
+	switch name {
+	case "required":
+		switch fv.Kind() {
+		case reflect.String:
+			return RequireNotEmpty(path, fv.String(), prev)
+		case reflect.Slice, reflect.Map:
+			return Require(path, ValidationCodeRequired, violationMessage(ValidationCodeRequired, path, path+" is required"), fv.Len() > 0, prev)
+		}
+		return Require(path, ValidationCodeRequired, violationMessage(ValidationCodeRequired, path, path+" is required"), !fv.IsZero(), prev)
+	case "min", "max":
+		return sv.applyBound(fv, path, name, param, prev)
+	case "email", "url", "uuid":
+		if fv.Kind() != reflect.String {
+			sv.err = fmt.Errorf("rule %s is only supported for strings, but %s is %s", name, path, fv.Kind())
+			return prev
+		}
+		switch name {
+		case "email":
+			return RequireEmail(path, fv.String(), prev)
+		case "url":
+			return RequireURL(path, fv.String(), prev)
+		}
+		return RequireUUID(path, fv.String(), prev)
+	case "oneof":
+		return RequireEnumValue(path, fmt.Sprint(fv.Interface()), strings.Fields(param), prev)
+	}
+	if sv.strict {
+		sv.err = errors.New("unknown validation rule '" + name + "' for " + path)
+	}
+	return prev
+}
+
+func (sv *structValidator) applyBound(fv reflect.Value, path, name, param string, prev *ValidationError) *ValidationError {
+	isMin := name == "min"
+	invalidParam := func() *ValidationError {
+		sv.err = errors.New("invalid parameter '" + param + "' for rule " + name + " of " + path)
+		return prev
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 0 {
+			return invalidParam()
+		}
+		if isMin {
+			return RequireMinLength(path, n, fv.String(), prev)
+		}
+		return RequireMaxLength(path, n, fv.String(), prev)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, err := strconv.Atoi(param)
+		if err != nil || n < 0 {
+			return invalidParam()
+		}
+		if isMin {
+			return Require(path, ValidationCodeTooFewItems, violationMessage(ValidationCodeTooFewItems, path, "Too few items in "+path), fv.Len() >= n, prev)
+		}
+		return Require(path, ValidationCodeTooManyItems, violationMessage(ValidationCodeTooManyItems, path, "Too many items in "+path), fv.Len() <= n, prev)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return invalidParam()
+		}
+		if isMin {
+			return RequireMin(path, n, fv.Int(), prev)
+		}
+		return RequireMax(path, n, fv.Int(), prev)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return invalidParam()
+		}
+		if isMin {
+			return RequireMin(path, n, fv.Uint(), prev)
+		}
+		return RequireMax(path, n, fv.Uint(), prev)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return invalidParam()
+		}
+		if isMin {
+			return RequireMin(path, n, fv.Float(), prev)
+		}
+		return RequireMax(path, n, fv.Float(), prev)
+	}
+	sv.err = errors.New("rule " + name + " is not supported for " + path)
+	return prev
+}
+
+type validateRule struct {
+	name  string
+	param string
+}
+
+func parseValidateTag(tag string) []validateRule {
+	rules := make([]validateRule, 0)
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		rules = append(rules, validateRule{name: name, param: param})
+	}
+	return rules
+}
+
+// isEmptyValue reports whether v is an empty string, slice or map.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
+
+// fieldName returns the name of the field in its JSON representation.
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
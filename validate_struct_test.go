@@ -0,0 +1,142 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	Street string `json:"street" validate:"required"`
+}
+
+type testItem struct {
+	Name     string `json:"name" validate:"required,max=5"`
+	Quantity int    `json:"quantity" validate:"min=1,max=10"`
+}
+
+type testOrder struct {
+	Email    string       `json:"email" validate:"required,email"`
+	Website  string       `json:"website" validate:"url"`
+	ID       string       `json:"id" validate:"uuid"`
+	Status   string       `json:"status" validate:"oneof=open closed"`
+	Note     string       `json:"note" validate:"min=3"`
+	Address  testAddress  `json:"address"`
+	Billing  *testAddress `json:"billing"`
+	Items    []testItem   `json:"items" validate:"required,max=2"`
+	Discount float64      `json:"discount" validate:"max=0.5"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	order := testOrder{
+		Email:    "invalid",
+		Status:   "pending",
+		Items:    []testItem{{Name: "ok", Quantity: 1}, {Name: "toolong", Quantity: 0}},
+		Discount: 0.75,
+	}
+
+	v := ValidateStruct(&order)
+	if v == nil {
+		t.Fatalf("Expected violations")
+	}
+	expected := []struct{ field, code string }{
+		{"email", ValidationCodeInvalid},
+		{"status", ValidationCodeInvalid},
+		{"address.street", ValidationCodeRequired},
+		{"items[1].name", ValidationCodeTooLong},
+		{"items[1].quantity", ValidationCodeTooSmall},
+		{"discount", ValidationCodeTooLarge},
+	}
+	if len(v.Errors) != len(expected) {
+		t.Fatalf("Expected %d violations, got %+v", len(expected), v.Errors)
+	}
+	for i, e := range expected {
+		if v.Errors[i].Field != e.field || v.Errors[i].Code != e.code {
+			t.Errorf("Expected %s with code %s at position %d, got %+v", e.field, e.code, i, v.Errors[i])
+		}
+	}
+}
+
+func TestValidateStruct_Valid(t *testing.T) {
+	order := testOrder{
+		Email:   "john@example.com",
+		Website: "https://example.com",
+		ID:      "123e4567-e89b-12d3-a456-426614174000",
+		Status:  "open",
+		Address: testAddress{Street: "Main St"},
+		Billing: &testAddress{Street: "Side St"},
+		Items:   []testItem{{Name: "ok", Quantity: 10}},
+	}
+
+	if v := ValidateStruct(order); v != nil {
+		t.Errorf("Expected no violations, got %+v", v.Errors)
+	}
+}
+
+func TestContext_BindJSON_ValidateTags(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"email":"john@example.com","address":{"street":"Main St"},"items":[]}`))
+	c, _ := newTestContext(req)
+
+	var order testOrder
+	res := c.BindJSON(&order)
+	if res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected 400 response, got %v", res)
+	}
+	w := httptest.NewRecorder()
+	if err := res.Write(w); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var body ValidationError
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(body.Errors) != 1 || body.Errors[0].Field != "items" {
+		t.Errorf("Expected items violation, got %+v", body.Errors)
+	}
+}
+
+func TestValidateStruct_UnknownRulePanics(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected panic for unknown rule")
+		}
+	}()
+	ValidateStruct(&struct {
+		Name string `validate:"omitempty"`
+	}{Name: "x"})
+}
+
+func TestContext_BindJSON_ForeignValidateTags(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name" validate:"required,omitempty,gte=3"`
+		Count *int   `json:"count" validate:"min=abc"`
+	}
+	tests := []struct {
+		body   string
+		status int
+	}{
+		{`{"name":"pen"}`, 0},
+		{`{}`, http.StatusBadRequest},
+		{`{"name":"pen","count":2}`, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		c, _ := newTestContext(httptest.NewRequest("POST", "/", strings.NewReader(tt.body)))
+		var data payload
+		res := c.BindJSON(&data)
+		if tt.status == 0 {
+			if res != nil {
+				t.Errorf("%s: Expected no response, got %d", tt.body, res.StatusCode)
+			}
+			continue
+		}
+		if res == nil || res.StatusCode != tt.status {
+			t.Errorf("%s: Expected %d, got %v", tt.body, tt.status, res)
+		}
+	}
+}
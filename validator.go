@@ -5,7 +5,9 @@
 package srv
 
 import (
+	"cmp"
 	"fmt"
+	"net/url"
 	"regexp"
 	"slices"
 )
@@ -17,8 +19,23 @@ const (
 	ValidationCodeTooShort     = "too_short"
 	ValidationCodeTooLong      = "too_long"
 	ValidationCodeInvalid      = "invalid"
+	ValidationCodeTooSmall     = "too_small"
+	ValidationCodeTooLarge     = "too_large"
 )
 
+// EmailPattern is the pattern used by RequireEmail. It accepts the common dot-atom form
+// of addresses with a domain consisting of at least two labels.
+var EmailPattern = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+
+// UUIDPattern is the pattern used by RequireUUID. It accepts the canonical 8-4-4-4-12 hex form.
+var UUIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// MessageFunc, if set, returns the message of violations created by the validators in this package,
+// e.g. to translate or rephrase them. It receives the violation code and the (formatted) field name.
+// If MessageFunc is nil or returns an empty string, the default English message is used.
+// Messages passed explicitly to Require, RequireIndexed and RequireTrue are not affected.
+var MessageFunc func(code, field string) string
+
 // Validatable represents an object that can be validated.
 type Validatable interface {
 	// Validate validates the object and returns an error if the object is invalid.
@@ -35,6 +52,50 @@ func (e *ValidationError) Error() string {
 	return "validation error"
 }
 
+// IsEmpty returns true if there are no violations. It is safe to call on a nil ValidationError.
+func (e *ValidationError) IsEmpty() bool {
+	return e == nil || len(e.Errors) == 0
+}
+
+// HasField returns true if there is at least one violation for the given field.
+func (e *ValidationError) HasField(field string) bool {
+	if e == nil {
+		return false
+	}
+	for _, v := range e.Errors {
+		if v.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldCodes returns the codes of all violations for the given field.
+func (e *ValidationError) FieldCodes(field string) []string {
+	codes := make([]string, 0)
+	if e == nil {
+		return codes
+	}
+	for _, v := range e.Errors {
+		if v.Field == field {
+			codes = append(codes, v.Code)
+		}
+	}
+	return codes
+}
+
+// Merge appends the violations of other to e and returns the result.
+// If either of them is nil, the other one is returned.
+func (e *ValidationError) Merge(other *ValidationError) *ValidationError {
+	if other == nil {
+		return e
+	}
+	if e == nil {
+		return other
+	}
+	return merge(e, other.Errors...)
+}
+
 type Violation struct {
 	Field   string `json:"field"`
 	Code    string `json:"code"`
@@ -71,6 +132,44 @@ func RequireIndexed(fieldFormat string, index int, code string, messageFormat st
 	})
 }
 
+// RequireEqual validates that two values are equal, e.g. a password and its confirmation.
+// It returns a ValidationError with ValidationCodeInvalid for field if the values differ.
+// If the values are equal, it returns the previous ValidationError unchanged.
+func RequireEqual[T comparable](field string, a, b T, prev *ValidationError) *ValidationError {
+	if a == b {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, field, "Value for "+field+" does not match"),
+	})
+}
+
+// RequireTrue validates a condition and returns a ValidationError with ValidationCodeInvalid
+// and the given message if the condition is false.
+// If the condition is true, it returns the previous ValidationError unchanged.
+// This is useful for rules spanning multiple fields, e.g. an end date that must be after a start date.
+func RequireTrue(field string, cond bool, message string, prev *ValidationError) *ValidationError {
+	if cond {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeInvalid,
+		Message: message,
+	})
+}
+
+// RequireWhen applies the validation rules in fn only if the condition is true.
+// If the condition is false, it returns the previous ValidationError unchanged.
+func RequireWhen(cond bool, fn func(prev *ValidationError) *ValidationError, prev *ValidationError) *ValidationError {
+	if !cond {
+		return prev
+	}
+	return fn(prev)
+}
+
 // RequireNotEmpty validates that a string value is not empty.
 // It returns a ValidationError with ValidationCodeRequired if the value is empty.
 // If the value is not empty, it returns the previous ValidationError unchanged.
@@ -81,7 +180,7 @@ func RequireNotEmpty(field string, value string, prev *ValidationError) *Validat
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeRequired,
-		Message: field + " is required",
+		Message: violationMessage(ValidationCodeRequired, field, field+" is required"),
 	})
 }
 
@@ -98,7 +197,7 @@ func RequireNotEmptyIndexed(fieldFormat string, index int, value string, prev *V
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeRequired,
-		Message: f + " is required",
+		Message: violationMessage(ValidationCodeRequired, f, f+" is required"),
 	})
 }
 
@@ -115,7 +214,7 @@ func RequireMinLength(field string, min int, value string, prev *ValidationError
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeTooShort,
-		Message: "Value for " + field + " is too short",
+		Message: violationMessage(ValidationCodeTooShort, field, "Value for "+field+" is too short"),
 	})
 }
 
@@ -135,7 +234,7 @@ func RequireMinLengthIndexed(fieldFormat string, index int, min int, value strin
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeTooShort,
-		Message: "Value for " + f + " is too short",
+		Message: violationMessage(ValidationCodeTooShort, f, "Value for "+f+" is too short"),
 	})
 }
 
@@ -152,7 +251,7 @@ func RequireMaxLength(field string, max int, value string, prev *ValidationError
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeTooLong,
-		Message: "Value for " + field + " is too long",
+		Message: violationMessage(ValidationCodeTooLong, field, "Value for "+field+" is too long"),
 	})
 }
 
@@ -172,10 +271,91 @@ func RequireMaxLengthIndexed(fieldFormat string, index int, max int, value strin
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeTooLong,
-		Message: "Value for " + f + " is too long",
+		Message: violationMessage(ValidationCodeTooLong, f, "Value for "+f+" is too long"),
+	})
+}
+
+// RequireMin validates that a value is greater than or equal to min.
+// It returns a ValidationError with ValidationCodeTooSmall if the value is less than min.
+// If the value meets the minimum, it returns the previous ValidationError unchanged.
+func RequireMin[T cmp.Ordered](field string, min, value T, prev *ValidationError) *ValidationError {
+	if value >= min {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeTooSmall,
+		Message: violationMessage(ValidationCodeTooSmall, field, "Value for "+field+" is too small"),
+	})
+}
+
+// RequireMinIndexed validates that a value is greater than or equal to min.
+// It returns a ValidationError with ValidationCodeTooSmall if the value is less than min.
+// If the value meets the minimum, it returns the previous ValidationError unchanged.
+// The field name is formatted using the fieldFormat string and the index.
+func RequireMinIndexed[T cmp.Ordered](fieldFormat string, index int, min, value T, prev *ValidationError) *ValidationError {
+	if value >= min {
+		return prev
+	}
+	f := fmt.Sprintf(fieldFormat, index)
+	return merge(prev, Violation{
+		Field:   f,
+		Code:    ValidationCodeTooSmall,
+		Message: violationMessage(ValidationCodeTooSmall, f, "Value for "+f+" is too small"),
 	})
 }
 
+// RequireMax validates that a value is less than or equal to max.
+// It returns a ValidationError with ValidationCodeTooLarge if the value is greater than max.
+// If the value meets the maximum, it returns the previous ValidationError unchanged.
+func RequireMax[T cmp.Ordered](field string, max, value T, prev *ValidationError) *ValidationError {
+	if value <= max {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeTooLarge,
+		Message: violationMessage(ValidationCodeTooLarge, field, "Value for "+field+" is too large"),
+	})
+}
+
+// RequireMaxIndexed validates that a value is less than or equal to max.
+// It returns a ValidationError with ValidationCodeTooLarge if the value is greater than max.
+// If the value meets the maximum, it returns the previous ValidationError unchanged.
+// The field name is formatted using the fieldFormat string and the index.
+func RequireMaxIndexed[T cmp.Ordered](fieldFormat string, index int, max, value T, prev *ValidationError) *ValidationError {
+	if value <= max {
+		return prev
+	}
+	f := fmt.Sprintf(fieldFormat, index)
+	return merge(prev, Violation{
+		Field:   f,
+		Code:    ValidationCodeTooLarge,
+		Message: violationMessage(ValidationCodeTooLarge, f, "Value for "+f+" is too large"),
+	})
+}
+
+// RequireRange validates that a value is within the inclusive range [min, max].
+// It returns a ValidationError with ValidationCodeTooSmall or ValidationCodeTooLarge if the value is out of range.
+// If the value is within the range, it returns the previous ValidationError unchanged.
+func RequireRange[T cmp.Ordered](field string, min, max, value T, prev *ValidationError) *ValidationError {
+	if min > max {
+		panic("min must be less than or equal to max")
+	}
+	return RequireMax(field, max, value, RequireMin(field, min, value, prev))
+}
+
+// RequireRangeIndexed validates that a value is within the inclusive range [min, max].
+// It returns a ValidationError with ValidationCodeTooSmall or ValidationCodeTooLarge if the value is out of range.
+// If the value is within the range, it returns the previous ValidationError unchanged.
+// The field name is formatted using the fieldFormat string and the index.
+func RequireRangeIndexed[T cmp.Ordered](fieldFormat string, index int, min, max, value T, prev *ValidationError) *ValidationError {
+	if min > max {
+		panic("min must be less than or equal to max")
+	}
+	return RequireMaxIndexed(fieldFormat, index, max, value, RequireMinIndexed(fieldFormat, index, min, value, prev))
+}
+
 // RequireEnumValue validates that a value is in the allowed list.
 // It returns a ValidationError with ValidationCodeInvalid if the value is not in the allowed list.
 // If the value is in the allowed list, it returns the previous ValidationError unchanged.
@@ -186,7 +366,7 @@ func RequireEnumValue[T comparable](field string, value T, allowed []T, prev *Va
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeInvalid,
-		Message: "Value for " + field + " is invalid",
+		Message: violationMessage(ValidationCodeInvalid, field, "Value for "+field+" is invalid"),
 	})
 }
 
@@ -202,7 +382,7 @@ func RequireEnumValueIndexed[T comparable](fieldFormat string, index int, value
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeInvalid,
-		Message: "Value for " + f + " is invalid",
+		Message: violationMessage(ValidationCodeInvalid, f, "Value for "+f+" is invalid"),
 	})
 }
 
@@ -216,7 +396,7 @@ func RequireRegex(field string, value string, pattern *regexp.Regexp, prev *Vali
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeInvalid,
-		Message: "Value for " + field + " is invalid",
+		Message: violationMessage(ValidationCodeInvalid, field, "Value for "+field+" is invalid"),
 	})
 }
 
@@ -231,7 +411,94 @@ func RequireRegexIndexed(fieldFormat string, index int, value string, pattern *r
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeInvalid,
-		Message: "Value for " + f + " is invalid",
+		Message: violationMessage(ValidationCodeInvalid, f, "Value for "+f+" is invalid"),
+	})
+}
+
+// RequireEmail validates that a string value is an email address.
+// It returns a ValidationError with ValidationCodeInvalid if the value is not a valid email address.
+// If the value is valid, it returns the previous ValidationError unchanged.
+func RequireEmail(field string, value string, prev *ValidationError) *ValidationError {
+	if isEmail(value) {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, field, "Value for "+field+" is not a valid email address"),
+	})
+}
+
+// RequireEmailIndexed validates that a string value is an email address.
+// It returns a ValidationError with ValidationCodeInvalid if the value is not a valid email address.
+// If the value is valid, it returns the previous ValidationError unchanged.
+func RequireEmailIndexed(fieldFormat string, index int, value string, prev *ValidationError) *ValidationError {
+	if isEmail(value) {
+		return prev
+	}
+	f := fmt.Sprintf(fieldFormat, index)
+	return merge(prev, Violation{
+		Field:   f,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, f, "Value for "+f+" is not a valid email address"),
+	})
+}
+
+// RequireURL validates that a string value is an absolute URL with a scheme and a host.
+// It returns a ValidationError with ValidationCodeInvalid if the value is not a valid URL.
+// If the value is valid, it returns the previous ValidationError unchanged.
+func RequireURL(field string, value string, prev *ValidationError) *ValidationError {
+	if isURL(value) {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, field, "Value for "+field+" is not a valid URL"),
+	})
+}
+
+// RequireURLIndexed validates that a string value is an absolute URL with a scheme and a host.
+// It returns a ValidationError with ValidationCodeInvalid if the value is not a valid URL.
+// If the value is valid, it returns the previous ValidationError unchanged.
+func RequireURLIndexed(fieldFormat string, index int, value string, prev *ValidationError) *ValidationError {
+	if isURL(value) {
+		return prev
+	}
+	f := fmt.Sprintf(fieldFormat, index)
+	return merge(prev, Violation{
+		Field:   f,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, f, "Value for "+f+" is not a valid URL"),
+	})
+}
+
+// RequireUUID validates that a string value is a UUID in canonical form.
+// It returns a ValidationError with ValidationCodeInvalid if the value is not a valid UUID.
+// If the value is valid, it returns the previous ValidationError unchanged.
+func RequireUUID(field string, value string, prev *ValidationError) *ValidationError {
+	if UUIDPattern.MatchString(value) {
+		return prev
+	}
+	return merge(prev, Violation{
+		Field:   field,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, field, "Value for "+field+" is not a valid UUID"),
+	})
+}
+
+// RequireUUIDIndexed validates that a string value is a UUID in canonical form.
+// It returns a ValidationError with ValidationCodeInvalid if the value is not a valid UUID.
+// If the value is valid, it returns the previous ValidationError unchanged.
+func RequireUUIDIndexed(fieldFormat string, index int, value string, prev *ValidationError) *ValidationError {
+	if UUIDPattern.MatchString(value) {
+		return prev
+	}
+	f := fmt.Sprintf(fieldFormat, index)
+	return merge(prev, Violation{
+		Field:   f,
+		Code:    ValidationCodeInvalid,
+		Message: violationMessage(ValidationCodeInvalid, f, "Value for "+f+" is not a valid UUID"),
 	})
 }
 
@@ -245,7 +512,7 @@ func RequireNotEmptySlice[T any](field string, value []T, prev *ValidationError)
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeRequired,
-		Message: "Value for " + field + " is required",
+		Message: violationMessage(ValidationCodeRequired, field, "Value for "+field+" is required"),
 	})
 }
 
@@ -260,7 +527,7 @@ func RequireNotEmptySliceIndexed[T any](fieldFormat string, index int, value []T
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeRequired,
-		Message: "Value for " + f + " is required",
+		Message: violationMessage(ValidationCodeRequired, f, "Value for "+f+" is required"),
 	})
 }
 
@@ -274,7 +541,7 @@ func RequireMinLengthSlice[T any](field string, min int, value []T, prev *Valida
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeTooFewItems,
-		Message: "Too few items in " + field,
+		Message: violationMessage(ValidationCodeTooFewItems, field, "Too few items in "+field),
 	})
 }
 
@@ -292,7 +559,7 @@ func RequireMinLengthSliceIndexed[T any](fieldFormat string, index int, min int,
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeTooFewItems,
-		Message: "Too few items in " + f,
+		Message: violationMessage(ValidationCodeTooFewItems, f, "Too few items in "+f),
 	})
 }
 
@@ -306,7 +573,7 @@ func RequireMaxLengthSlice[T any](field string, max int, value []T, prev *Valida
 	return merge(prev, Violation{
 		Field:   field,
 		Code:    ValidationCodeTooManyItems,
-		Message: "Too many items in " + field,
+		Message: violationMessage(ValidationCodeTooManyItems, field, "Too many items in "+field),
 	})
 }
 
@@ -325,7 +592,7 @@ func RequireMaxLengthSliceIndexed[T any](fieldFormat string, index int, max int,
 	return merge(prev, Violation{
 		Field:   f,
 		Code:    ValidationCodeTooManyItems,
-		Message: "Too many items in " + f,
+		Message: violationMessage(ValidationCodeTooManyItems, f, "Too many items in "+f),
 	})
 }
 
@@ -338,6 +605,25 @@ func Validate(v *ValidationError) error {
 	return v
 }
 
+func isEmail(value string) bool {
+	return len(value) <= 254 && EmailPattern.MatchString(value)
+}
+
+func isURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+// violationMessage returns the message for a violation, using MessageFunc if set.
+func violationMessage(code, field, defaultMessage string) string {
+	if MessageFunc != nil {
+		if msg := MessageFunc(code, field); msg != "" {
+			return msg
+		}
+	}
+	return defaultMessage
+}
+
 func merge(prev *ValidationError, v ...Violation) *ValidationError {
 	if prev != nil {
 		prev.Errors = append(prev.Errors, v...)
@@ -0,0 +1,139 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import "testing"
+
+func TestRequireRange(t *testing.T) {
+	var v *ValidationError
+	v = RequireRange("age", 18, 99, 42, v)
+	if v != nil {
+		t.Fatalf("Expected no violation, got %v", v.Errors)
+	}
+
+	v = RequireRange("age", 18, 99, 12, v)
+	v = RequireRangeIndexed("items[%d].price", 1, 0.0, 10.0, 12.5, v)
+	if v == nil || len(v.Errors) != 2 {
+		t.Fatalf("Expected 2 violations, got %v", v)
+	}
+	if v.Errors[0].Field != "age" || v.Errors[0].Code != ValidationCodeTooSmall {
+		t.Errorf("Expected age to be too small, got %+v", v.Errors[0])
+	}
+	if v.Errors[1].Field != "items[1].price" || v.Errors[1].Code != ValidationCodeTooLarge {
+		t.Errorf("Expected items[1].price to be too large, got %+v", v.Errors[1])
+	}
+}
+
+func TestRequireEmail(t *testing.T) {
+	valid := []string{"john@example.com", "john.doe+tag@sub.example.co.uk", "a_b-c@ex-ample.org"}
+	for _, value := range valid {
+		if v := RequireEmail("email", value, nil); v != nil {
+			t.Errorf("Expected %s to be valid", value)
+		}
+	}
+	invalid := []string{"", "john", "john@", "@example.com", "john@example", "john@-example.com", "john doe@example.com"}
+	for _, value := range invalid {
+		v := RequireEmailIndexed("emails[%d]", 2, value, nil)
+		if v == nil {
+			t.Errorf("Expected %s to be invalid", value)
+			continue
+		}
+		if v.Errors[0].Field != "emails[2]" || v.Errors[0].Code != ValidationCodeInvalid {
+			t.Errorf("Expected emails[2] to be invalid, got %+v", v.Errors[0])
+		}
+	}
+}
+
+func TestRequireURL(t *testing.T) {
+	valid := []string{"https://example.com", "http://localhost:8080/path?q=1", "ftp://files.example.com/a"}
+	for _, value := range valid {
+		if v := RequireURL("url", value, nil); v != nil {
+			t.Errorf("Expected %s to be valid", value)
+		}
+	}
+	invalid := []string{"", "example.com", "/relative/path", "https://", "://example.com"}
+	for _, value := range invalid {
+		if v := RequireURLIndexed("urls[%d]", 0, value, nil); v == nil {
+			t.Errorf("Expected %s to be invalid", value)
+		}
+	}
+}
+
+func TestRequireUUID(t *testing.T) {
+	if v := RequireUUID("id", "123e4567-e89b-12d3-a456-426614174000", nil); v != nil {
+		t.Errorf("Expected UUID to be valid, got %v", v.Errors)
+	}
+	for _, value := range []string{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"} {
+		if v := RequireUUIDIndexed("ids[%d]", 0, value, nil); v == nil {
+			t.Errorf("Expected %s to be invalid", value)
+		}
+	}
+}
+
+func TestRequireCrossField(t *testing.T) {
+	password, confirm := "secret", "secrte"
+	start, end := 10, 5
+	shipping := false
+
+	var v *ValidationError
+	v = RequireEqual("confirmPassword", password, confirm, v)
+	v = RequireTrue("endDate", end > start, "endDate must be after startDate", v)
+	v = RequireWhen(shipping, func(prev *ValidationError) *ValidationError {
+		return RequireNotEmpty("address", "", prev)
+	}, v)
+
+	if v == nil || len(v.Errors) != 2 {
+		t.Fatalf("Expected 2 violations, got %v", v)
+	}
+	if v.Errors[0].Field != "confirmPassword" {
+		t.Errorf("Expected confirmPassword violation, got %+v", v.Errors[0])
+	}
+	if v.Errors[1].Field != "endDate" || v.Errors[1].Message != "endDate must be after startDate" {
+		t.Errorf("Expected endDate violation, got %+v", v.Errors[1])
+	}
+}
+
+func TestMessageFunc(t *testing.T) {
+	MessageFunc = func(code, field string) string {
+		if code == ValidationCodeTooShort {
+			return field + " ist zu kurz"
+		}
+		return ""
+	}
+	defer func() { MessageFunc = nil }()
+
+	v := RequireMinLengthIndexed("names[%d]", 0, 3, "ab", nil)
+	v = RequireNotEmpty("email", "", v)
+
+	if v.Errors[0].Message != "names[0] ist zu kurz" {
+		t.Errorf("Expected translated message, got %s", v.Errors[0].Message)
+	}
+	if v.Errors[1].Message != "email is required" {
+		t.Errorf("Expected default message, got %s", v.Errors[1].Message)
+	}
+}
+
+func TestValidationError_Accessors(t *testing.T) {
+	var empty *ValidationError
+	if !empty.IsEmpty() || empty.HasField("name") || len(empty.FieldCodes("name")) != 0 {
+		t.Errorf("Expected nil ValidationError to be empty")
+	}
+
+	first := RequireNotEmpty("name", "", nil)
+	first = RequireMinLength("name", 3, "", first)
+	second := RequireEmail("email", "invalid", nil)
+
+	v := empty.Merge(first).Merge(second).Merge(nil)
+	if v.IsEmpty() {
+		t.Fatalf("Expected violations")
+	}
+	if !v.HasField("name") || !v.HasField("email") || v.HasField("age") {
+		t.Errorf("Expected violations for name and email, got %+v", v.Errors)
+	}
+	codes := v.FieldCodes("name")
+	if len(codes) != 2 || codes[0] != ValidationCodeRequired || codes[1] != ValidationCodeTooShort {
+		t.Errorf("Expected codes [required too_short], got %v", codes)
+	}
+}
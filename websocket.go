@@ -0,0 +1,140 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// websocketGUID is appended to the Sec-WebSocket-Key to compute the Sec-WebSocket-Accept value (RFC 6455).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// ErrBadHandshake is returned by Context.Upgrade if the request is not a valid WebSocket handshake.
+var ErrBadHandshake = errors.New("bad websocket handshake")
+
+// ErrOriginNotAllowed is returned by Context.Upgrade if the Origin of the request is not allowed.
+var ErrOriginNotAllowed = errors.New("websocket origin not allowed")
+
+// Upgrade performs the WebSocket opening handshake (RFC 6455) and hijacks the connection.
+// responseHeader is added to the 101 Switching Protocols response, e.g. to select a subprotocol.
+// The caller is responsible for framing messages on the returned connection and for closing it.
+// Returns ErrBadHandshake if the request is not a valid handshake and an error if responseHeader contains
+// an invalid name or value, e.g. with a line break; nothing is written in these cases, so the handler can
+// respond with an error. After a successful upgrade, the Response returned by the handler is not written.
+//
+// Browsers don't apply the same-origin policy to WebSockets, so to prevent cross-site WebSocket hijacking,
+// Upgrade returns ErrOriginNotAllowed if the Origin header is not one of allowedOrigins, e.g.
+// "https://example.com". Without allowedOrigins, the origin must match the request's own scheme and host,
+// like in VerifyOrigin. Requests without Origin header, which browsers always send, are accepted.
+func (c *Context) Upgrade(responseHeader http.Header, allowedOrigins ...string) (net.Conn, *bufio.ReadWriter, error) {
+	key := c.Header("Sec-WebSocket-Key")
+	if c.r.Method != http.MethodGet ||
+		!headerContainsToken(c.r.Header, "Connection", "upgrade") ||
+		!headerContainsToken(c.r.Header, "Upgrade", "websocket") ||
+		c.Header("Sec-WebSocket-Version") != "13" {
+		return nil, nil, ErrBadHandshake
+	}
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != 16 {
+		return nil, nil, ErrBadHandshake
+	}
+	if origin := c.Origin(); origin != "" && !c.originAllowed(origin, allowedOrigins) {
+		return nil, nil, ErrOriginNotAllowed
+	}
+	for k, vals := range responseHeader {
+		if !validHeaderFieldName(k) {
+			return nil, nil, fmt.Errorf("invalid response header name %q", k)
+		}
+		for _, v := range vals {
+			if !validHeaderFieldValue(v) {
+				return nil, nil, fmt.Errorf("invalid value for response header %q", k)
+			}
+		}
+	}
+	conn, brw, err := c.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	sb.WriteString("Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n")
+	for k, vals := range responseHeader {
+		for _, v := range vals {
+			sb.WriteString(http.CanonicalHeaderKey(k) + ": " + v + "\r\n")
+		}
+	}
+	sb.WriteString("\r\n")
+	if _, err := brw.WriteString(sb.String()); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if err := brw.Flush(); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, brw, nil
+}
+
+// Hijack takes over the underlying connection of the request, e.g. to use a WebSocket library.
+// After a successful hijack, the Response returned by the handler is not written, so the handler may
+// return Respond().Hijacked().
+// Returns an error wrapping http.ErrNotSupported if the connection can't be hijacked, e.g. for HTTP/2.
+func (c *Context) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(c.w).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	c.hijacked = true
+	return conn, brw, nil
+}
+
+func websocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// headerContainsToken reports whether the comma separated values of header contain token, ignoring case.
+func headerContainsToken(h http.Header, header, token string) bool {
+	for _, v := range h.Values(header) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validHeaderFieldName reports whether name is a valid header field name, i.e. a token (RFC 9110).
+func validHeaderFieldName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 0x80 || c <= ' ' || c == 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// validHeaderFieldValue reports whether value contains no control characters other than horizontal tab,
+// so that it can't inject further header lines.
+func validHeaderFieldValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
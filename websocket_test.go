@@ -0,0 +1,128 @@
+// Copyright 2025 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package srv
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext_Upgrade(t *testing.T) {
+	s := NewServer()
+	s.GET("/ws", func(c *Context) *Response {
+		conn, brw, err := c.Upgrade(http.Header{"Sec-WebSocket-Protocol": {"chat"}})
+		if err != nil {
+			return Respond().BadRequest(ErrorDto{Code: "BadHandshake", Message: err.Error()})
+		}
+		defer conn.Close()
+		line, _ := brw.ReadString('\n')
+		_, _ = brw.WriteString("echo " + line)
+		_ = brw.Flush()
+		return Respond()
+	})
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(ts.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: example.com\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected status 101, got %d", res.StatusCode)
+	}
+	if accept := res.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Expected accept hash s3pPLMBiTxaQ9kYGzzhZRbK+xOo=, got %s", accept)
+	}
+	if res.Header.Get("Sec-WebSocket-Protocol") != "chat" {
+		t.Errorf("Expected subprotocol chat, got %s", res.Header.Get("Sec-WebSocket-Protocol"))
+	}
+
+	_, _ = io.WriteString(conn, "hello\n")
+	if line, _ := br.ReadString('\n'); line != "echo hello\n" {
+		t.Errorf("Expected echo over hijacked connection, got %q", line)
+	}
+}
+
+func TestContext_Upgrade_BadHandshake(t *testing.T) {
+	s := NewServer()
+	s.GET("/ws", func(c *Context) *Response {
+		if _, _, err := c.Upgrade(nil); err != nil {
+			return Respond().BadRequest()
+		}
+		return Respond()
+	})
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/ws", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestContext_Upgrade_InvalidResponseHeader(t *testing.T) {
+	for _, h := range []http.Header{
+		{"Sec-WebSocket-Protocol": {"chat\r\nSet-Cookie: session=evil"}},
+		{"X-Evil\r\nSet-Cookie": {"session=evil"}},
+		{"X Space": {"value"}},
+	} {
+		req := httptest.NewRequest("GET", "/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		c, _ := newTestContext(req)
+
+		if _, _, err := c.Upgrade(h); err == nil || errors.Is(err, ErrBadHandshake) {
+			t.Errorf("Expected invalid header error for %v, got %v", h, err)
+		}
+		if c.hijacked {
+			t.Errorf("Expected connection not to be hijacked for %v", h)
+		}
+	}
+}
+
+func TestContext_Upgrade_Origin(t *testing.T) {
+	tests := []struct {
+		origin  string
+		allowed []string
+		denied  bool
+	}{
+		{"", nil, false},
+		{"http://example.com", nil, false},
+		{"https://evil.com", nil, true},
+		{"https://app.example.com", []string{"https://app.example.com"}, false},
+		{"http://example.com", []string{"https://app.example.com"}, true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "http://example.com/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		c, _ := newTestContext(req)
+
+		_, _, err := c.Upgrade(nil, tt.allowed...)
+		if denied := errors.Is(err, ErrOriginNotAllowed); denied != tt.denied {
+			t.Errorf("%q %v: Expected denied %t, got %v", tt.origin, tt.allowed, tt.denied, err)
+		}
+	}
+}